@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// how often firing alerts are sent to the Alertmanager again.  Firing alerts say they end
+// after four of these, so a couple of failed sends don't resolve them.
+const alertmanagerResendInterval = time.Minute
+
+// struct for the Alertmanager route output
+type AlertmanagerOutput struct {
+	URL string `json:"url"` // base url, alerts are posted to /api/v2/alerts under it
+}
+
+// an alert in the Alertmanager v2 api, see postableAlert in its openapi spec
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      *time.Time        `json:"endsAt,omitempty"` // only queued for resolved alerts, firing ones get theirs on every attempt
+}
+
+// validate checks the Alertmanager url
+func (output *AlertmanagerOutput) validate() error {
+	target, err := url.Parse(output.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("alertmanager url must be an absolute http or https url")
+	}
+	return nil
+}
+
+// alertmanagerPayload converts alerts to the body of a POST /api/v2/alerts.  Firing alerts are
+// left without an endsAt, it's set when the payload is sent so a retry doesn't send one that has
+// already passed.
+func alertmanagerPayload(alerts []Alert) ([]byte, error) {
+	var postable []alertmanagerAlert
+	for _, alert := range alerts {
+		labels := maps.Clone(alert.Labels)
+		// the Alertmanager groups and routes on alertname
+		labels["alertname"] = alert.Rule
+
+		annotations := map[string]string{
+			"summary":  fmt.Sprintf("%s on %s", alert.Rule, alert.DeviceID),
+			"alert_id": alert.ID,
+		}
+		if alert.Value != nil {
+			annotations["value"] = strconv.FormatFloat(*alert.Value, 'g', -1, 64)
+		}
+
+		startsAt := alert.ActiveSince
+		if alert.FiredAt != nil {
+			startsAt = *alert.FiredAt
+		}
+		postable = append(postable, alertmanagerAlert{
+			Labels:      labels,
+			Annotations: annotations,
+			StartsAt:    startsAt,
+			EndsAt:      alert.ResolvedAt,
+		})
+	}
+	return json.Marshal(postable)
+}
+
+// stampEndsAt sets the endsAt of the firing alerts in a payload to four resend intervals from now
+func stampEndsAt(payload []byte, now time.Time) ([]byte, error) {
+	var postable []alertmanagerAlert
+	if err := json.Unmarshal(payload, &postable); err != nil {
+		return nil, err
+	}
+	endsAt := now.Add(4 * alertmanagerResendInterval)
+	for i := range postable {
+		if postable[i].EndsAt == nil {
+			postable[i].EndsAt = &endsAt
+		}
+	}
+	return json.Marshal(postable)
+}
+
+// postAlertmanager makes a single attempt at posting alerts to the Alertmanager
+func (s *Server) postAlertmanager(ctx context.Context, output *AlertmanagerOutput, payload []byte) error {
+	payload, err := stampEndsAt(payload, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	target, err := url.JoinPath(output.URL, "/api/v2/alerts")
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.webhookClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("alertmanager responded with %s", response.Status)
+	}
+	return nil
+}
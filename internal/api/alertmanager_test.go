@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStampEndsAt(t *testing.T) {
+	now := time.Now().UTC()
+	resolvedAt := now.Add(-time.Minute)
+	payload, err := alertmanagerPayload([]Alert{
+		{ID: "1", Rule: "offline", DeviceID: "cam1", State: AlertFiring, Labels: map[string]string{"device_id": "cam1"}, ActiveSince: now},
+		{ID: "2", Rule: "offline", DeviceID: "cam2", State: AlertResolved, Labels: map[string]string{"device_id": "cam2"}, ActiveSince: now, ResolvedAt: &resolvedAt},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a retry ten minutes later is still four resend intervals ahead of when it was sent
+	for _, at := range []time.Time{now, now.Add(10 * time.Minute)} {
+		stamped, err := stampEndsAt(payload, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var postable []alertmanagerAlert
+		if err := json.Unmarshal(stamped, &postable); err != nil {
+			t.Fatal(err)
+		}
+		if want := at.Add(4 * alertmanagerResendInterval); postable[0].EndsAt == nil || !postable[0].EndsAt.Equal(want) {
+			t.Errorf("firing alert sent at %s: got endsAt %v, want %s", at, postable[0].EndsAt, want)
+		}
+		if postable[1].EndsAt == nil || !postable[1].EndsAt.Equal(resolvedAt) {
+			t.Errorf("resolved alert sent at %s: got endsAt %v, want %s", at, postable[1].EndsAt, resolvedAt)
+		}
+	}
+}
+
+func TestPostAlertmanager(t *testing.T) {
+	var received []alertmanagerAlert
+	status := http.StatusInternalServerError
+	alertmanager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/alerts" || r.Method != http.MethodPost {
+			t.Errorf("got %s %s, want POST /api/v2/alerts", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		received = nil
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("got body %s: %v", body, err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer alertmanager.Close()
+
+	server := NewServer(map[string]Device{}, map[string][]Heartbeat{}, map[string][]DeviceStats{}, Options{WebhookTimeout: 5 * time.Second})
+	output := &AlertmanagerOutput{URL: alertmanager.URL}
+	payload, err := alertmanagerPayload([]Alert{{ID: "1", Rule: "offline", DeviceID: "cam1", State: AlertFiring, Labels: map[string]string{"group": "site-a"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first attempt fails, the retry goes through with a fresh endsAt
+	if err := server.postAlertmanager(context.Background(), output, payload); err == nil {
+		t.Error("got no error for a 500")
+	}
+	status = http.StatusOK
+	before := time.Now()
+	if err := server.postAlertmanager(context.Background(), output, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(received))
+	}
+	alert := received[0]
+	if alert.Labels["alertname"] != "offline" || alert.Labels["group"] != "site-a" || alert.Annotations["alert_id"] != "1" {
+		t.Errorf("got labels %v and annotations %v", alert.Labels, alert.Annotations)
+	}
+	if want := before.Add(4 * alertmanagerResendInterval); alert.EndsAt == nil || alert.EndsAt.Before(want.Add(-time.Second)) {
+		t.Errorf("got endsAt %v, want about %s", alert.EndsAt, want)
+	}
+}
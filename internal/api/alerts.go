@@ -0,0 +1,621 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fleetsy/internal/rules"
+	"fleetsy/internal/sketch"
+	"fleetsy/pkg/api"
+)
+
+// states an alert can be in
+const (
+	AlertPending  = "pending"  // the expression matched but hasn't held for long enough yet
+	AlertFiring   = "firing"   // the expression has held for the rule's for duration
+	AlertResolved = "resolved" // the expression stopped matching after the alert fired
+)
+
+// events sent to webhooks as alerts change state
+const (
+	EventAlertFiring    = "alert.firing"
+	EventAlertResolved  = "alert.resolved"
+	EventAlertEscalated = "alert.escalated" // still firing and unacknowledged after the rule's escalate_after
+)
+
+// ErrRuleNotFound is returned when an alert rule id hasn't been registered
+var ErrRuleNotFound = errors.New("rule not found")
+
+const (
+	// only the most recent evaluations are kept for each rule
+	maxRuleHistory = 100
+	// only the most recent alerts are kept, resolved ones are dropped first
+	maxAlerts = 10000
+)
+
+// struct for the alert rule registry
+type AlertRule struct {
+	ID          string `json:"id"`
+	Expr        string `json:"expr"` // as it was given, eg uptime(24h) < 95 for 30m
+	Scope       string `json:"scope"`
+	ScopeID     string `json:"scope_id"` // device id or group name
+	Severity    string `json:"severity"`
+	Description string `json:"description,omitempty"`
+	// firing alerts that haven't been acknowledged after this long are escalated, eg 15m
+	EscalateAfter string `json:"escalate_after,omitempty"`
+
+	expr          rules.Expr
+	escalateAfter time.Duration
+}
+
+// struct for the incoming rule PUT requests
+type RulePut struct {
+	Expr          string `json:"expr"`
+	Scope         string `json:"scope"`
+	ScopeID       string `json:"scope_id"`
+	Severity      string `json:"severity"`
+	Description   string `json:"description"`
+	EscalateAfter string `json:"escalate_after"`
+}
+
+// response struct for the rules GET requests
+type RulesGet struct {
+	Rules []AlertRule `json:"rules"`
+}
+
+// struct for the alert store, there's at most one pending or firing alert for each rule and device
+type Alert struct {
+	ID          string            `json:"id"`
+	Rule        string            `json:"rule"`
+	DeviceID    string            `json:"device_id"`
+	State       string            `json:"state"`
+	Labels      map[string]string `json:"labels"`
+	Value       *float64          `json:"value,omitempty"` // the latest value of the expression, nanoseconds for upload times
+	ActiveSince time.Time         `json:"active_since"`    // when the expression started matching
+	FiredAt     *time.Time        `json:"fired_at,omitempty"`
+	ResolvedAt  *time.Time        `json:"resolved_at,omitempty"`
+	// silences matching the alert, nothing is sent to the webhooks while there are any
+	SilencedBy     []string   `json:"silenced_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	EscalatedAt    *time.Time `json:"escalated_at,omitempty"`
+
+	notified     bool      // alert.firing was sent, so alert.resolved should be too
+	silenced     bool      // a silence matched the last time the alert was progressed
+	escalateFrom time.Time // when the escalation clock started, when the alert fired or its last silence ended
+}
+
+// response struct for the alerts GET requests
+type AlertsGet struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+// the result of evaluating a rule for one device
+type RuleResult struct {
+	DeviceID string   `json:"device_id"`
+	Value    *float64 `json:"value,omitempty"` // nil when there was no data in the window
+	Matched  bool     `json:"matched"`
+	State    string   `json:"state,omitempty"` // the device's alert state after the evaluation
+}
+
+// struct for the rule history arrays
+type RuleEvaluation struct {
+	At      time.Time    `json:"at"`
+	Results []RuleResult `json:"results"`
+}
+
+// response struct for the rule history GET requests
+type RuleHistoryGet struct {
+	Evaluations []RuleEvaluation `json:"evaluations"`
+}
+
+// an alert state change to send to the webhooks once the alert lock is released
+type alertChange struct {
+	event string
+	alert Alert
+}
+
+// (GET /rules)
+func (s *Server) GetRules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, RulesGet{Rules: s.alertRuleList()})
+}
+
+// (GET /rules/{rule})
+func (s *Server) GetRulesRule(w http.ResponseWriter, r *http.Request, rule api.RulePathParam) {
+	s.alertMutex.Lock()
+	alertRule, found := s.alertRules[rule]
+	s.alertMutex.Unlock()
+
+	if !found {
+		writeError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	writeJSON(w, alertRule)
+}
+
+// (PUT /rules/{rule})
+func (s *Server) PutRulesRule(w http.ResponseWriter, r *http.Request, rule api.RulePathParam) {
+	var newData RulePut
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	alertRule := AlertRule{
+		ID:            rule,
+		Expr:          newData.Expr,
+		Scope:         newData.Scope,
+		ScopeID:       newData.ScopeID,
+		Severity:      newData.Severity,
+		Description:   newData.Description,
+		EscalateAfter: newData.EscalateAfter,
+	}
+	if err := s.registerRule(alertRule); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (DELETE /rules/{rule})
+func (s *Server) DeleteRulesRule(w http.ResponseWriter, r *http.Request, rule api.RulePathParam) {
+	changes, err := s.deleteRule(rule)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	s.notifyAlerts(changes)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (GET /rules/{rule}/history)
+func (s *Server) GetRulesRuleHistory(w http.ResponseWriter, r *http.Request, rule api.RulePathParam) {
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	if _, found := s.alertRules[rule]; !found {
+		writeError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+
+	// newest first, like the alerts
+	response := RuleHistoryGet{Evaluations: []RuleEvaluation{}}
+	history := s.ruleHistory[rule]
+	for i := len(history) - 1; i >= 0; i-- {
+		response.Evaluations = append(response.Evaluations, history[i])
+	}
+	writeJSON(w, response)
+}
+
+// (GET /alerts)
+func (s *Server) GetAlerts(w http.ResponseWriter, r *http.Request, params api.GetAlertsParams) {
+	if params.State != nil {
+		switch *params.State {
+		case AlertPending, AlertFiring, AlertResolved:
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("state must be %s, %s or %s", AlertPending, AlertFiring, AlertResolved))
+			return
+		}
+	}
+
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	now := time.Now().UTC()
+	response := AlertsGet{Alerts: []Alert{}}
+	for i := len(s.alertOrder) - 1; i >= 0; i-- {
+		// a copy, the stored alert's silences are only updated when it's evaluated
+		alert := *s.alerts[s.alertOrder[i]]
+		// silences can start and end between evaluations
+		if alert.State != AlertResolved {
+			alert.SilencedBy = s.silencedBy(alert.Labels, now)
+		}
+		if params.State != nil && alert.State != *params.State {
+			continue
+		}
+		if params.Rule != nil && alert.Rule != *params.Rule {
+			continue
+		}
+		if params.DeviceId != nil && alert.DeviceID != *params.DeviceId {
+			continue
+		}
+		response.Alerts = append(response.Alerts, alert)
+	}
+	writeJSON(w, response)
+}
+
+// registerRule validates a rule and adds or replaces it in the registry.
+// Alerts already raised by a replaced rule carry on and are checked against the new expression.
+func (s *Server) registerRule(alertRule AlertRule) error {
+	expr, err := rules.Parse(alertRule.Expr)
+	if err != nil {
+		return err
+	}
+	alertRule.expr = expr
+	if alertRule.EscalateAfter != "" {
+		alertRule.escalateAfter, err = rules.ParseDuration(alertRule.EscalateAfter)
+		if err != nil || alertRule.escalateAfter <= 0 {
+			return errors.New("escalate_after must be a positive duration, eg 15m")
+		}
+	}
+	if alertRule.Severity == "" {
+		alertRule.Severity = SeverityWarning
+	}
+
+	if err := s.validateScope(alertRule.Scope, alertRule.ScopeID); err != nil {
+		return err
+	}
+
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	s.alertRules[alertRule.ID] = alertRule
+	return nil
+}
+
+// deleteRule removes a rule and its history, resolving any alerts it was firing
+func (s *Server) deleteRule(id string) ([]alertChange, error) {
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	if _, found := s.alertRules[id]; !found {
+		return nil, ErrRuleNotFound
+	}
+
+	var changes []alertChange
+	now := time.Now().UTC()
+	for deviceId := range s.activeAlerts[id] {
+		if change, changed := s.clearAlert(id, deviceId, now); changed {
+			changes = append(changes, change)
+		}
+	}
+	delete(s.alertRules, id)
+	delete(s.ruleHistory, id)
+	delete(s.activeAlerts, id)
+	return changes, nil
+}
+
+// alertRuleList copies the registry, sorted by id
+func (s *Server) alertRuleList() []AlertRule {
+	s.alertMutex.Lock()
+	list := make([]AlertRule, 0, len(s.alertRules))
+	for _, alertRule := range s.alertRules {
+		list = append(list, alertRule)
+	}
+	s.alertMutex.Unlock()
+
+	slices.SortFunc(list, func(a, b AlertRule) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return list
+}
+
+// RunAlerts evaluates every rule until ctx is cancelled
+func (s *Server) RunAlerts(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	defer s.workerStarted(WorkerAlerts, every)()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.evaluateRules(now.UTC())
+			s.workerRan(WorkerAlerts)
+		}
+	}
+}
+
+// evaluateRules works out every rule for the devices it covers, updates the alerts and
+// sends the state changes to the webhooks
+func (s *Server) evaluateRules(now time.Time) {
+	var changes []alertChange
+	for _, alertRule := range s.alertRuleList() {
+		values, devices := s.ruleValues(alertRule, now)
+		changes = append(changes, s.applyRule(alertRule, values, devices, now)...)
+	}
+	s.notifyAlerts(changes)
+	s.resendAlertmanager(s.firingAlerts(now), now)
+}
+
+// firingAlerts copies the firing alerts that receivers have been told about and that no
+// silence matches now
+func (s *Server) firingAlerts(now time.Time) []Alert {
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	var firing []Alert
+	for _, id := range s.alertOrder {
+		alert := s.alerts[id]
+		if alert.State == AlertFiring && alert.notified && len(s.silencedBy(alert.Labels, now)) == 0 {
+			firing = append(firing, *alert)
+		}
+	}
+	return firing
+}
+
+// ruleValues works out the value of a rule's expression for every device in its scope.
+// Devices without data in the window have a nil value.
+func (s *Server) ruleValues(alertRule AlertRule, now time.Time) (map[string]*float64, map[string]Device) {
+	values := make(map[string]*float64)
+	devices := make(map[string]Device)
+
+	// lock the mutex for reading
+	s.rlockDevices(context.Background())
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.RUnlock()
+
+	for deviceId, device := range s.devices {
+		if !inScope(deviceId, device, alertRule.Scope, alertRule.ScopeID) {
+			continue
+		}
+		devices[deviceId] = device
+		values[deviceId] = s.ruleValue(alertRule.expr, deviceId, device, now)
+	}
+	return values, devices
+}
+
+// ruleValue works out an expression for one device over the window ending at now.
+// The caller must hold the read lock.
+func (s *Server) ruleValue(expr rules.Expr, deviceId string, device Device, now time.Time) *float64 {
+	from := now.Add(-expr.Window)
+	inWindow := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(now)
+	}
+
+	var value float64
+	switch expr.Func {
+	case rules.FuncUptime:
+		interval := deviceTypeFor(device.Model).HeartbeatInterval()
+		// the same as the SLOs, slots from before the server could have heard from the device
+		// aren't missed
+		observed, expected := countSlots(s.deviceHeartbeatMap[deviceId], s.heardSince(device, from), now, interval, s.options.UptimeTolerance)
+		if expected == 0 {
+			return nil
+		}
+		value = float64(observed) / float64(expected) * 100
+	case rules.FuncHeartbeats:
+		for _, heartbeat := range s.deviceHeartbeatMap[deviceId] {
+			if inWindow(heartbeat.SentAt) {
+				value++
+			}
+		}
+	case rules.FuncUploads:
+		for _, stats := range s.deviceStatsMap[deviceId] {
+			if stats.UploadTime > 0 && inWindow(stats.SentAt) {
+				value++
+			}
+		}
+	case rules.FuncFailureRate:
+		var window []DeviceStats
+		for _, stats := range s.deviceStatsMap[deviceId] {
+			if inWindow(stats.SentAt) {
+				window = append(window, stats)
+			}
+		}
+		return calculateFailureRate(window)
+	default:
+		// the aggregates work on the upload times in the window
+		uploadTimes := sketch.New(uploadTimeAccuracy)
+		for _, stats := range s.deviceStatsMap[deviceId] {
+			if stats.UploadTime > 0 && inWindow(stats.SentAt) {
+				uploadTimes.Add(float64(stats.UploadTime))
+			}
+		}
+		if uploadTimes.Count() == 0 {
+			return nil
+		}
+		switch expr.Func {
+		case rules.FuncAvg:
+			value = uploadTimes.Mean()
+		case rules.FuncMin:
+			value = uploadTimes.Min()
+		case rules.FuncMax:
+			value = uploadTimes.Max()
+		case rules.FuncP50:
+			value = uploadTimes.Quantile(0.50)
+		case rules.FuncP90:
+			value = uploadTimes.Quantile(0.90)
+		case rules.FuncP95:
+			value = uploadTimes.Quantile(0.95)
+		case rules.FuncP99:
+			value = uploadTimes.Quantile(0.99)
+		}
+	}
+	return &value
+}
+
+// applyRule moves a rule's alerts along with the latest values and records the evaluation.
+// Returns the alerts that started or stopped firing.
+func (s *Server) applyRule(alertRule AlertRule, values map[string]*float64, devices map[string]Device, now time.Time) []alertChange {
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	// the rule could have been deleted while its values were worked out
+	if _, found := s.alertRules[alertRule.ID]; !found {
+		return nil
+	}
+	if s.activeAlerts[alertRule.ID] == nil {
+		s.activeAlerts[alertRule.ID] = make(map[string]string)
+	}
+	active := s.activeAlerts[alertRule.ID]
+
+	var changes []alertChange
+	evaluation := RuleEvaluation{At: now, Results: []RuleResult{}}
+	deviceIds := make([]string, 0, len(values))
+	for deviceId := range values {
+		deviceIds = append(deviceIds, deviceId)
+	}
+	slices.Sort(deviceIds)
+
+	for _, deviceId := range deviceIds {
+		value := values[deviceId]
+		// no data never matches, a device that goes quiet is caught by uptime and heartbeats rules
+		matched := value != nil && alertRule.expr.Compare(*value)
+		result := RuleResult{DeviceID: deviceId, Value: value, Matched: matched}
+
+		alertId, isActive := active[deviceId]
+		switch {
+		case matched && !isActive:
+			s.nextAlertID++
+			alertId = strconv.Itoa(s.nextAlertID)
+			s.alerts[alertId] = &Alert{
+				ID:          alertId,
+				Rule:        alertRule.ID,
+				DeviceID:    deviceId,
+				State:       AlertPending,
+				Labels:      alertLabels(alertRule, deviceId, devices[deviceId]),
+				ActiveSince: now,
+			}
+			s.alertOrder = append(s.alertOrder, alertId)
+			active[deviceId] = alertId
+			fallthrough
+		case matched:
+			alert := s.alerts[alertId]
+			alert.Value = value
+			if alert.State == AlertPending && now.Sub(alert.ActiveSince) >= alertRule.expr.For {
+				alert.State = AlertFiring
+				alert.FiredAt = &now
+			}
+			if change, changed := s.progressAlert(alertRule, alert, now); changed {
+				changes = append(changes, change)
+			}
+			result.State = alert.State
+		case isActive:
+			s.alerts[alertId].Value = value
+			if change, changed := s.clearAlert(alertRule.ID, deviceId, now); changed {
+				changes = append(changes, change)
+				result.State = AlertResolved
+			}
+		}
+		evaluation.Results = append(evaluation.Results, result)
+	}
+
+	// devices that have left the rule's scope can't keep an alert open
+	for deviceId := range active {
+		if _, found := values[deviceId]; !found {
+			if change, changed := s.clearAlert(alertRule.ID, deviceId, now); changed {
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	history := append(s.ruleHistory[alertRule.ID], evaluation)
+	if len(history) > maxRuleHistory {
+		history = history[len(history)-maxRuleHistory:]
+	}
+	s.ruleHistory[alertRule.ID] = history
+	s.trimAlerts()
+	return changes
+}
+
+// progressAlert sends a firing alert to the webhooks once it isn't silenced, and escalates it
+// if it goes unacknowledged for too long.  A silenced alert can't escalate, the clock starts
+// again when the silence ends.  The caller must hold the alert lock.
+func (s *Server) progressAlert(alertRule AlertRule, alert *Alert, now time.Time) (alertChange, bool) {
+	alert.SilencedBy = s.silencedBy(alert.Labels, now)
+	wasSilenced := alert.silenced
+	alert.silenced = len(alert.SilencedBy) > 0
+	if alert.State != AlertFiring || alert.silenced {
+		return alertChange{}, false
+	}
+
+	if !alert.notified {
+		alert.notified = true
+		// held back by a silence until now
+		if alert.FiredAt.Before(now) {
+			alert.FiredAt = &now
+		}
+		alert.escalateFrom = now
+		return alertChange{event: EventAlertFiring, alert: *alert}, true
+	}
+	// the silence on an alert that was already sent just ended
+	if wasSilenced {
+		alert.escalateFrom = now
+	}
+
+	if alertRule.escalateAfter > 0 && alert.AcknowledgedAt == nil && alert.EscalatedAt == nil &&
+		now.Sub(alert.escalateFrom) >= alertRule.escalateAfter {
+		alert.EscalatedAt = &now
+		return alertChange{event: EventAlertEscalated, alert: *alert}, true
+	}
+	return alertChange{}, false
+}
+
+// clearAlert ends a device's active alert for a rule.  A firing alert is resolved, a pending one
+// never fired so it's dropped.  The caller must hold the alert lock.
+func (s *Server) clearAlert(ruleId, deviceId string, now time.Time) (alertChange, bool) {
+	alertId, found := s.activeAlerts[ruleId][deviceId]
+	if !found {
+		return alertChange{}, false
+	}
+	delete(s.activeAlerts[ruleId], deviceId)
+
+	alert := s.alerts[alertId]
+	if alert.State == AlertPending {
+		delete(s.alerts, alertId)
+		s.alertOrder = slices.DeleteFunc(s.alertOrder, func(id string) bool { return id == alertId })
+		return alertChange{}, false
+	}
+	alert.State = AlertResolved
+	alert.ResolvedAt = &now
+	// receivers never heard it was firing, so there's nothing to resolve
+	if !alert.notified {
+		return alertChange{}, false
+	}
+	return alertChange{event: EventAlertResolved, alert: *alert}, true
+}
+
+// trimAlerts drops the oldest resolved alerts once there are too many.
+// The caller must hold the alert lock.
+func (s *Server) trimAlerts() {
+	excess := len(s.alertOrder) - maxAlerts
+	if excess <= 0 {
+		return
+	}
+	s.alertOrder = slices.DeleteFunc(s.alertOrder, func(id string) bool {
+		if excess > 0 && s.alerts[id].State == AlertResolved {
+			delete(s.alerts, id)
+			excess--
+			return true
+		}
+		return false
+	})
+}
+
+// alertLabels describes what an alert is about, so receivers can route and group it
+func alertLabels(alertRule AlertRule, deviceId string, device Device) map[string]string {
+	labels := map[string]string{
+		"rule":      alertRule.ID,
+		"severity":  alertRule.Severity,
+		"device_id": deviceId,
+		"model":     device.Model,
+	}
+	if device.Group != "" {
+		labels["group"] = device.Group
+	}
+	return labels
+}
+
+// notifyAlerts sends alert state changes to the webhooks and alert routes.  It takes the
+// webhook lock, so the alert lock must already be released.
+func (s *Server) notifyAlerts(changes []alertChange) {
+	for _, change := range changes {
+		alert := change.alert
+		s.notify(change.event, alert.DeviceID, func(Webhook) any { return alert }, nil)
+		s.notifyRoutes(change.event, alert)
+	}
+}
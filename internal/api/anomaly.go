@@ -0,0 +1,193 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"fleetsy/pkg/api"
+)
+
+// series that are watched for anomalies
+const (
+	AnomalyUploadTime        = "upload_time"
+	AnomalyHeartbeatInterval = "heartbeat_interval" // time between consecutive heartbeats
+)
+
+// severities of an anomaly
+const (
+	SeverityWarning  = "warning"  // the z-score passed the configured threshold
+	SeverityCritical = "critical" // the z-score passed twice the configured threshold
+)
+
+const (
+	// how quickly the baseline follows the data, higher values forget the past faster
+	anomalyAlpha = 0.1
+	// samples needed before the baseline is trusted enough to flag anything
+	anomalyWarmup = 10
+	// the standard deviation is never taken as less than this fraction of the mean, otherwise a
+	// device that always heartbeats exactly on the minute would flag a one second wobble
+	anomalyMinRelStdDev = 0.05
+	// only the most recent anomalies are kept for each device
+	maxDeviceAnomalies = 1000
+)
+
+// struct for the device anomaly arrays
+type Anomaly struct {
+	Metric     string    `json:"metric"`
+	Severity   string    `json:"severity"`
+	SentAt     time.Time `json:"sent_at"`     // when the anomalous record was sent
+	DetectedAt time.Time `json:"detected_at"` // when the server flagged it
+	Value      string    `json:"value"`       // the anomalous value as a duration string. Eg: 5m10s
+	Expected   string    `json:"expected"`    // the baseline mean at the time as a duration string
+	ZScore     float64   `json:"z_score"`     // how many standard deviations the value was from the baseline
+}
+
+// response struct for the anomalies GET requests
+type AnomaliesGet struct {
+	Anomalies []Anomaly `json:"anomalies"`
+}
+
+// ewma tracks an exponentially weighted mean and variance of a series
+type ewma struct {
+	mean     float64
+	variance float64
+	count    int
+}
+
+// zScore returns how far a value is from the baseline, false until the baseline has warmed up
+func (e *ewma) zScore(value float64) (float64, bool) {
+	if e.count < anomalyWarmup {
+		return 0, false
+	}
+	stdDev := max(math.Sqrt(e.variance), math.Abs(e.mean)*anomalyMinRelStdDev)
+	if stdDev == 0 {
+		return 0, false
+	}
+	return (value - e.mean) / stdDev, true
+}
+
+// update folds a value into the baseline
+func (e *ewma) update(value float64) {
+	e.count++
+	if e.count == 1 {
+		e.mean = value
+		return
+	}
+	diff := value - e.mean
+	increment := anomalyAlpha * diff
+	e.mean += increment
+	e.variance = (1 - anomalyAlpha) * (e.variance + diff*increment)
+}
+
+// per device baselines, kept up to date as records are stored
+type anomalyBaseline struct {
+	uploadTime        ewma
+	heartbeatInterval ewma
+	lastHeartbeat     time.Time
+}
+
+// (GET /devices/{device_id}/anomalies)
+func (s *Server) GetDevicesDeviceIdAnomalies(w http.ResponseWriter, r *http.Request, deviceId string, params api.GetDevicesDeviceIdAnomaliesParams) {
+	if params.Metric != nil && *params.Metric != AnomalyUploadTime && *params.Metric != AnomalyHeartbeatInterval {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("metric must be %s or %s", AnomalyUploadTime, AnomalyHeartbeatInterval))
+		return
+	}
+	if params.Severity != nil && *params.Severity != SeverityWarning && *params.Severity != SeverityCritical {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("severity must be %s or %s", SeverityWarning, SeverityCritical))
+		return
+	}
+
+	// lock the mutex for reading
+	s.rlockDevices(r.Context())
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.RUnlock()
+
+	anomalies, found := s.deviceAnomalies[deviceId]
+	if !found {
+		writeNotFound(w)
+		return
+	}
+
+	response := AnomaliesGet{Anomalies: []Anomaly{}}
+	for _, anomaly := range anomalies {
+		if params.Metric != nil && anomaly.Metric != *params.Metric {
+			continue
+		}
+		// critical anomalies are also warnings, so asking for warnings returns both
+		if params.Severity != nil && *params.Severity == SeverityCritical && anomaly.Severity != SeverityCritical {
+			continue
+		}
+		if params.From != nil && anomaly.SentAt.Before(*params.From) {
+			continue
+		}
+		if params.To != nil && !anomaly.SentAt.Before(*params.To) {
+			continue
+		}
+		response.Anomalies = append(response.Anomalies, anomaly)
+	}
+
+	writeJSON(w, response)
+}
+
+// checkHeartbeatAnomaly compares the gap since the device's previous heartbeat with its baseline.
+// The caller must hold the write lock.
+func (s *Server) checkHeartbeatAnomaly(deviceId string, heartbeat Heartbeat) {
+	baseline := s.anomalyBaselines[deviceId]
+	previous := baseline.lastHeartbeat
+	if heartbeat.SentAt.After(previous) {
+		baseline.lastHeartbeat = heartbeat.SentAt
+	}
+	// the first heartbeat has nothing to compare with, and a late arrival out of order
+	// says nothing about the cadence
+	if previous.IsZero() || !heartbeat.SentAt.After(previous) {
+		return
+	}
+
+	gap := float64(heartbeat.SentAt.Sub(previous))
+	s.checkAnomaly(deviceId, AnomalyHeartbeatInterval, &baseline.heartbeatInterval, gap, heartbeat.SentAt, heartbeat.ReceivedAt)
+}
+
+// checkUploadTimeAnomaly compares an upload with the device's baseline.
+// The caller must hold the write lock.
+func (s *Server) checkUploadTimeAnomaly(deviceId string, stats DeviceStats) {
+	if stats.UploadTime <= 0 {
+		return
+	}
+	baseline := s.anomalyBaselines[deviceId]
+	s.checkAnomaly(deviceId, AnomalyUploadTime, &baseline.uploadTime, float64(stats.UploadTime), stats.SentAt, stats.ReceivedAt)
+}
+
+// checkAnomaly records an anomaly if the value is too far from the baseline, then folds the value in
+func (s *Server) checkAnomaly(deviceId, metric string, baseline *ewma, value float64, sentAt, receivedAt time.Time) {
+	// anomalous values still update the baseline so a lasting change becomes the new normal
+	defer baseline.update(value)
+
+	threshold := s.options.AnomalyThreshold
+	if threshold <= 0 {
+		return
+	}
+	z, ok := baseline.zScore(value)
+	if !ok || math.Abs(z) < threshold {
+		return
+	}
+
+	severity := SeverityWarning
+	if math.Abs(z) >= 2*threshold {
+		severity = SeverityCritical
+	}
+	anomalies := append(s.deviceAnomalies[deviceId], Anomaly{
+		Metric:     metric,
+		Severity:   severity,
+		SentAt:     sentAt,
+		DetectedAt: receivedAt,
+		Value:      time.Duration(value).String(),
+		Expected:   time.Duration(baseline.mean).String(),
+		ZScore:     z,
+	})
+	if len(anomalies) > maxDeviceAnomalies {
+		anomalies = anomalies[len(anomalies)-maxDeviceAnomalies:]
+	}
+	s.deviceAnomalies[deviceId] = anomalies
+}
@@ -2,10 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"fleetsy/internal/sketch"
 	// import the interface
 	"fleetsy/pkg/api"
 )
@@ -13,14 +17,130 @@ import (
 // Server implements the generated ServerInterface.
 type Server struct {
 	deviceMutex        sync.RWMutex
-	deviceHeartbeatMap map[string][]time.Time
+	devices            map[string]Device
+	deviceHeartbeatMap map[string][]Heartbeat
 	deviceStatsMap     map[string][]DeviceStats
+	deviceMetricsMap   map[string]map[string][]MetricSample // device id -> metric name -> samples
+	uploadTimeSketches map[string]*sketch.Sketch            // upload time distribution for each device, kept up to date by addStats
+	anomalyBaselines   map[string]*anomalyBaseline          // upload time and heartbeat cadence baselines for each device
+	deviceAnomalies    map[string][]Anomaly
+	lastUDPHeartbeat   map[string]time.Time // last timestamp accepted over UDP for each device, apart from the history so other paths can't move it
+	options            Options
+	metrics            *serverMetrics // the server's own Prometheus metrics
+
+	// the metric schemas registered for each device model
+	schemaMutex   sync.RWMutex
+	metricSchemas map[string]map[string]MetricSchema // model -> metric name -> schema
+
+	// the service level objectives, by id
+	sloMutex sync.RWMutex
+	slos     map[string]SLO
+
+	// whether each device is online, updated by heartbeats and RunPresence
+	presenceMutex sync.Mutex
+	presence      map[string]*devicePresence
+
+	// webhooks, alert routes and the notifications queued for them, delivered by RunWebhooks
+	webhookMutex       sync.Mutex
+	webhooks           map[string]Webhook
+	routes             map[string]*Route
+	notifications      map[string]*Notification
+	notificationOrder  []string // notification ids, oldest first
+	nextNotificationID int
+	webhookWake        chan struct{}
+	webhookClient      *http.Client
+
+	// alert rules and the alerts they raise, evaluated by RunAlerts
+	alertMutex   sync.Mutex
+	alertRules   map[string]AlertRule
+	ruleHistory  map[string][]RuleEvaluation // rule id -> evaluations, oldest first
+	alerts       map[string]*Alert
+	alertOrder   []string                     // alert ids, oldest first
+	activeAlerts map[string]map[string]string // rule id -> device id -> pending or firing alert id
+	nextAlertID  int
+	// silences are kept under the alert lock since every evaluation checks them
+	silences      map[string]*Silence
+	silenceOrder  []string // silence ids, oldest first
+	nextSilenceID int
+
+	// when the server started and the state of the background workers, for /healthz and /readyz
+	startedAt   time.Time
+	workerMutex sync.Mutex
+	workers     map[string]*workerState
+	// set while a readiness probe is queued for the device lock, so probes can't pile up behind it
+	readyLockWaiting atomic.Bool
+}
+
+// DefaultDeviceModel is used for devices that don't have a model in devices.csv
+const DefaultDeviceModel = "default"
+
+// struct for the devices loaded from devices.csv
+type Device struct {
+	ID    string `json:"device_id"`
+	Model string `json:"model"`
+	Group string `json:"group,omitempty"` // eg the site the device is installed at
+	// when the device was added to the fleet, the registration uptime mode counts from here
+	// defaults to when the server started
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// Options holds the tunable behaviour of the server
+type Options struct {
+	// records whose sent_at is further than this from the server's clock are marked as skewed
+	// zero disables skew detection
+	MaxClockSkew time.Duration
+	// replace the sent_at of skewed records with the time the server received them
+	CorrectClockSkew bool
+	// records whose z-score against the device's baseline is at least this are flagged as anomalies
+	// zero disables anomaly detection
+	AnomalyThreshold float64
+	// how uptime is worked out, one of UptimeModes.  Empty means UptimeModeSpan
+	UptimeMode string
+	// how late a heartbeat can be before the slot based uptime modes count it as missed
+	UptimeTolerance time.Duration
+	// how overdue a heartbeat can be before the device is late
+	PresenceGrace time.Duration
+	// how many heartbeat intervals without a heartbeat before the device is offline
+	OfflineAfterMissed int
+	// how many times a notification is sent before it is dead lettered
+	WebhookMaxAttempts int
+	// how long a single webhook delivery may take
+	WebhookTimeout time.Duration
+	// export uptime, upload time and heartbeat age gauges for every device on /metrics
+	MetricsDeviceGauges bool
+	// at most this many devices get gauges, zero for no limit
+	MetricsMaxDevices int
+	// only devices in these groups get gauges, every group when empty
+	MetricsDeviceGroups []string
+	// heartbeats, stats, metric samples and anomalies received longer ago than this are
+	// dropped by RunRetention, zero keeps everything
+	Retention time.Duration
+}
+
+// struct for the device heartbeat array
+type Heartbeat struct {
+	SentAt     time.Time `json:"sent_at"`     // timestamp used for stats, the reported time unless it was corrected
+	ReportedAt time.Time `json:"reported_at"` // sent_at exactly as the device reported it
+	ReceivedAt time.Time `json:"received_at"` // when the server received the heartbeat
+	Skewed     bool      `json:"skewed"`      // the device clock was off by more than the allowed skew
+	// the device answered over its websocket rather than sending a heartbeat, so every timestamp
+	// is the server's and says nothing about the device's clock
+	ServerTimed bool `json:"server_timed,omitempty"`
 }
 
 // struct for the device stats array
 type DeviceStats struct {
-	SentAt     time.Time `json:"sent_at"`
-	UploadTime int64     `json:"upload_time"` // upload time is in nanoseconds
+	SentAt        time.Time      `json:"sent_at"`
+	UploadTime    int64          `json:"upload_time"`              // upload time is in nanoseconds
+	BytesUploaded int64          `json:"bytes_uploaded,omitempty"` // zero when the device didn't report it
+	ClipDuration  int64          `json:"clip_duration,omitempty"`  // clip duration is in nanoseconds, zero when not reported
+	ResultCode    *int           `json:"result_code,omitempty"`    // 0 is success, anything else is a failure, nil when not reported
+	RetryCount    int            `json:"retry_count,omitempty"`
+	NetworkType   string         `json:"network_type,omitempty"` // eg wifi, lte
+	Extra         map[string]any `json:"extra,omitempty"`        // anything specific to the device type, see DeviceType
+	ReportedAt    time.Time      `json:"reported_at"`            // set by the store, same meaning as Heartbeat
+	ReceivedAt    time.Time      `json:"received_at"`            // set by the store, same meaning as Heartbeat
+	Skewed        bool           `json:"skewed"`                 // set by the store, same meaning as Heartbeat
 }
 
 // struct for the incoming heartbeat POST requests
@@ -32,20 +152,101 @@ type HeartbeatPost struct {
 type StatsPost struct {
 	SentAt     string `json:"sent_at"`
 	UploadTime int64  `json:"upload_time"` // upload time is in nanoseconds, use int64
+	// everything below is optional
+	BytesUploaded int64  `json:"bytes_uploaded"`
+	ClipDuration  int64  `json:"clip_duration"` // clip duration is in nanoseconds
+	ResultCode    *int   `json:"result_code"`   // pointer so a missing code isn't mistaken for success
+	RetryCount    int    `json:"retry_count"`
+	NetworkType   string `json:"network_type"`
+}
+
+// deviceStats converts the request into a record for the device stats array
+func (p StatsPost) deviceStats(sentAt time.Time) DeviceStats {
+	return DeviceStats{
+		SentAt:        sentAt,
+		UploadTime:    p.UploadTime,
+		BytesUploaded: p.BytesUploaded,
+		ClipDuration:  p.ClipDuration,
+		ResultCode:    p.ResultCode,
+		RetryCount:    p.RetryCount,
+		NetworkType:   p.NetworkType,
+	}
 }
 
 // response struct for the stats GET requests
 type StatsGet struct {
-	Uptime        float32 `json:"uptime"`
-	AvgUploadTime string  `json:"avg_upload_time"`
+	Uptime                 float32                 `json:"uptime"`
+	AvgUploadTime          string                  `json:"avg_upload_time"`
+	ClockOffset            string                  `json:"clock_offset,omitempty"`             // how far ahead (or behind if negative) the device clock is
+	ClockDriftPPM          *float64                `json:"clock_drift_ppm,omitempty"`          // how fast the offset is changing, in microseconds per second
+	SkewedRecords          int                     `json:"skewed_records,omitempty"`           // records whose skew exceeded the configured maximum
+	Throughput             *float64                `json:"throughput,omitempty"`               // average upload throughput in MB/s, only when bytes were reported
+	FailureRate            *float64                `json:"failure_rate,omitempty"`             // percentage of failed uploads, only when result codes were reported
+	UploadTimeDistribution *UploadTimeDistribution `json:"upload_time_distribution,omitempty"` // percentiles of upload time, only when uploads were reported
+	Details                map[string]float64      `json:"details,omitempty"`                  // stats specific to the device type, see DeviceType
+	Durations              map[string]Duration     `json:"durations,omitempty"`                // only when numeric_durations is requested
 }
 
 // NewServer creates a new instance with the required dependencies
-func NewServer(deviceDB map[string][]time.Time, statsDB map[string][]DeviceStats) *Server {
-	return &Server{
+func NewServer(devices map[string]Device, deviceDB map[string][]Heartbeat, statsDB map[string][]DeviceStats, options Options) *Server {
+	// every device gets an empty set of metrics, sketch and baseline, they're filled in as data arrives
+	metricsDB := make(map[string]map[string][]MetricSample)
+	sketches := make(map[string]*sketch.Sketch)
+	baselines := make(map[string]*anomalyBaseline)
+	anomalies := make(map[string][]Anomaly)
+	presence := make(map[string]*devicePresence)
+	startedAt := time.Now().UTC()
+	for deviceId, device := range devices {
+		if device.Model == "" {
+			device.Model = DefaultDeviceModel
+		}
+		if device.RegisteredAt.IsZero() {
+			device.RegisteredAt = startedAt
+		}
+		devices[deviceId] = device
+		metricsDB[deviceId] = make(map[string][]MetricSample)
+		sketches[deviceId] = sketch.New(uploadTimeAccuracy)
+		baselines[deviceId] = &anomalyBaseline{}
+		anomalies[deviceId] = []Anomaly{}
+		presence[deviceId] = &devicePresence{
+			Presence: Presence{State: PresenceUnknown, Since: startedAt},
+			interval: deviceTypeFor(device.Model).HeartbeatInterval(),
+		}
+	}
+
+	s := &Server{
+		devices:            devices,
 		deviceHeartbeatMap: deviceDB,
 		deviceStatsMap:     statsDB,
+		deviceMetricsMap:   metricsDB,
+		uploadTimeSketches: sketches,
+		anomalyBaselines:   baselines,
+		deviceAnomalies:    anomalies,
+		lastUDPHeartbeat:   make(map[string]time.Time),
+		options:            options,
+		metricSchemas:      make(map[string]map[string]MetricSchema),
+		slos:               make(map[string]SLO),
+		presence:           presence,
+		webhooks:           make(map[string]Webhook),
+		routes:             make(map[string]*Route),
+		notifications:      make(map[string]*Notification),
+		webhookWake:        make(chan struct{}, 1),
+		webhookClient:      &http.Client{Timeout: options.WebhookTimeout},
+		alertRules:         make(map[string]AlertRule),
+		ruleHistory:        make(map[string][]RuleEvaluation),
+		alerts:             make(map[string]*Alert),
+		activeAlerts:       make(map[string]map[string]string),
+		silences:           make(map[string]*Silence),
+		startedAt:          startedAt,
+		// the workers main starts, they're not running until their Run function is called
+		workers: map[string]*workerState{
+			WorkerPresence: {},
+			WorkerWebhooks: {},
+			WorkerAlerts:   {},
+		},
 	}
+	s.metrics = newServerMetrics(s)
+	return s
 }
 
 // Ensure that Server implements the ServerInterface at compile time.
@@ -56,36 +257,26 @@ func (s *Server) PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Req
 	// read the new heartbeat
 	var newData HeartbeatPost
 	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
 		// Normally I'd use http.StatusBadRequest but the spec says the only http codes allowed here are 204, 404, and 500
 		http.Error(w, "Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	// lock the mutex for writing
-	s.deviceMutex.Lock()
-	// defer to guarantee it's unlocked later
-	defer s.deviceMutex.Unlock()
-	// validate the device exists in the db
-	_, found := s.deviceHeartbeatMap[deviceId]
-	// return 404 if not found
-	if !found {
-		errorResponse := api.Error{Code: http.StatusNotFound, Message: "Device not found"}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(errorResponse)
-		return
-	}
-
 	// parse the timestamp
 	newTimestamp, tsError := time.Parse(time.RFC3339, newData.SentAt)
 	if tsError != nil {
+		recordError(r, tsError)
 		// Normally I'd use http.StatusBadRequest but the spec says the only http codes allowed here are 204, 404, and 500
 		http.Error(w, "Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	// insert the new data
-	s.deviceHeartbeatMap[deviceId] = append(s.deviceHeartbeatMap[deviceId], newTimestamp)
+	// insert the new data, return 404 if the device isn't in the db
+	if err := s.addHeartbeat(r.Context(), deviceId, newTimestamp); err != nil {
+		writeNotFound(w)
+		return
+	}
 
 	// send conformation of success
 	w.Header().Set("Content-Type", "application/json")
@@ -93,63 +284,17 @@ func (s *Server) PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Req
 }
 
 // (GET /devices/{device_id}/stats)
-func (s *Server) GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string) {
-
-	// lock the mutex for reading
-	s.deviceMutex.Lock()
-	// defer to guarantee it's unlocked later
-	defer s.deviceMutex.Unlock()
-
-	// validate the device exists in the db
-	deviceHeartbeats, heartbeatFound := s.deviceHeartbeatMap[deviceId]
-	deviceStats, statsFound := s.deviceStatsMap[deviceId]
+func (s *Server) GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string, params api.GetDevicesDeviceIdStatsParams) {
+	response, err := s.deviceStats(r.Context(), deviceId)
 	// return 404 if not found
-	if !heartbeatFound || !statsFound {
-		errorResponse := api.Error{Code: http.StatusNotFound, Message: "Device not found"}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(errorResponse)
+	if err != nil {
+		writeNotFound(w)
 		return
 	}
 
-	// calculate uptime
-	var uptime float32
-	// check array length first
-	if len(deviceHeartbeats) == 0 {
-		uptime = 0.0
-	} else {
-		sumHeartbeats := len(deviceHeartbeats)
-		firstTimestamp := deviceHeartbeats[0]
-		// assuming that all heartbeats were received in chronological order
-		lastTimestamp := deviceHeartbeats[len(deviceHeartbeats)-1]
-		// the devices are expected to send one heartbeat every minute
-		// so we need to use the number of minutes to calculate the uptime properly
-		// subtract the timestamps and convert
-		diff := lastTimestamp.Sub(firstTimestamp).Minutes()
-		// now calculate the uptime percentage
-		uptime = (float32(sumHeartbeats) / float32(diff)) * 100
-	}
-
-	// calculate upload time
-	var uploadTime string = ""
-	// check array length first
-	if len(deviceStats) == 0 {
-		uploadTime = ""
-	} else {
-		var totalSeconds float64 = 0
-		for _, stats := range deviceStats {
-			// convert to time.Duration to make some of this easier
-			dur := time.Duration(stats.UploadTime)
-			totalSeconds += dur.Seconds()
-		}
-		avg := totalSeconds / float64(len(deviceStats))
-		// time.Duration works in nanoseconds, so we need to convert seconds as part of this
-		// there are 1e9 nanoseconds in every second
-		uploadTime = time.Duration(avg * 1e9).String()
-	}
-	response := StatsGet{
-		Uptime:        uptime,
-		AvgUploadTime: uploadTime,
+	// the v1 duration strings stay as they are, the numeric forms are opt in
+	if params.NumericDurations != nil && *params.NumericDurations {
+		response.Durations = numericDurations(response)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -159,45 +304,54 @@ func (s *Server) GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request,
 
 // (POST /devices/{device_id}/stats)
 func (s *Server) PostDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string) {
-	// read the new heartbeat
-	var newData StatsPost
-	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+	// read the new stats, the payload depends on the device type so it's decoded by the type
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		recordError(r, err)
 		// Normally I'd use http.StatusBadRequest but the spec says the only http codes allowed here are 204, 404, and 500
 		http.Error(w, "Invalid request body", http.StatusInternalServerError)
 		return
 	}
 
-	// lock the mutex for writing
-	s.deviceMutex.Lock()
-	// defer to guarantee it's unlocked later
-	defer s.deviceMutex.Unlock()
-	// validate the device exists in the db
-	_, found := s.deviceStatsMap[deviceId]
-	// return 404 if not found
-	if !found {
-		errorResponse := api.Error{Code: http.StatusNotFound, Message: "Device not found"}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(errorResponse)
+	newDeviceStats, err := s.decodeStats(deviceId, payload)
+	if errors.Is(err, ErrDeviceNotFound) {
+		writeNotFound(w)
 		return
 	}
-
-	// parse the timestamp
-	newTimestamp, tsError := time.Parse(time.RFC3339, newData.SentAt)
-	if tsError != nil {
+	if err != nil {
+		recordError(r, err)
 		// Normally I'd use http.StatusBadRequest but the spec says the only http codes allowed here are 204, 404, and 500
 		http.Error(w, "Invalid request body", http.StatusInternalServerError)
 		return
 	}
 
-	newDeviceStats := DeviceStats{
-		SentAt:     newTimestamp,
-		UploadTime: newData.UploadTime,
+	// insert the new data, return 404 if the device isn't in the db
+	if err := s.addStats(r.Context(), deviceId, newDeviceStats); err != nil {
+		writeNotFound(w)
+		return
 	}
 
-	s.deviceStatsMap[deviceId] = append(s.deviceStatsMap[deviceId], newDeviceStats)
-
 	// send conformation of success
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// writeNotFound sends the standard 404 response for an unknown device
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "Device not found")
+}
+
+// writeError sends an error response in the same shape as the 404s
+func writeError(w http.ResponseWriter, code int, message string) {
+	errorResponse := api.Error{Code: int32(code), Message: message}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorResponse)
+}
+
+// writeJSON sends a 200 response with a json body
+func writeJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
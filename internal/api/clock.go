@@ -0,0 +1,110 @@
+package api
+
+import (
+	"slices"
+	"time"
+)
+
+// number of recent heartbeats used to estimate the current clock offset
+// a median over a window ignores the odd heartbeat that was delayed in transit
+const clockOffsetWindow = 60
+
+// drift isn't reported until the heartbeats cover this many heartbeat intervals and there are
+// at least this many of them.  Devices report sent_at to the second, so over a shorter span
+// the rounding alone swamps any real drift.
+const (
+	clockDriftMinIntervals = 10
+	clockDriftMinSamples   = 10
+)
+
+// ClockEstimate describes how a device's clock compares to the server's
+type ClockEstimate struct {
+	Offset   time.Duration // reported time minus received time, positive when the device clock is ahead
+	DriftPPM *float64      // change in offset in microseconds per second of server time, nil until there's enough history
+	Samples  int           // number of heartbeats the estimate is based on
+}
+
+// checkClockSkew applies the configured skew policy to a single record.
+// It returns the timestamp that should be used for stats and whether the record is skewed.
+func (s *Server) checkClockSkew(reportedAt, receivedAt time.Time) (time.Time, bool) {
+	if s.options.MaxClockSkew <= 0 {
+		return reportedAt, false
+	}
+
+	skew := reportedAt.Sub(receivedAt).Abs()
+	if skew <= s.options.MaxClockSkew {
+		return reportedAt, false
+	}
+	if s.options.CorrectClockSkew {
+		return receivedAt, true
+	}
+	return reportedAt, true
+}
+
+// estimateClock works out the offset and drift of a device clock from its heartbeats.
+// interval is how often the device is expected to send a heartbeat.
+func estimateClock(heartbeats []Heartbeat, interval time.Duration) ClockEstimate {
+	// heartbeats the server timestamped itself would pull the offset towards zero
+	deviceHeartbeats := make([]Heartbeat, 0, len(heartbeats))
+	for _, heartbeat := range heartbeats {
+		if !heartbeat.ServerTimed {
+			deviceHeartbeats = append(deviceHeartbeats, heartbeat)
+		}
+	}
+	if len(deviceHeartbeats) == 0 {
+		return ClockEstimate{}
+	}
+
+	// the current offset is the median over the most recent heartbeats
+	window := deviceHeartbeats[max(0, len(deviceHeartbeats)-clockOffsetWindow):]
+	offsets := make([]time.Duration, len(window))
+	for i, heartbeat := range window {
+		offsets[i] = heartbeat.ReportedAt.Sub(heartbeat.ReceivedAt)
+	}
+	slices.Sort(offsets)
+	estimate := ClockEstimate{
+		Offset:  offsets[len(offsets)/2],
+		Samples: len(deviceHeartbeats),
+	}
+
+	first := deviceHeartbeats[0].ReceivedAt
+	last := deviceHeartbeats[len(deviceHeartbeats)-1].ReceivedAt
+	if len(deviceHeartbeats) < clockDriftMinSamples || last.Sub(first) < clockDriftMinIntervals*interval {
+		return estimate
+	}
+
+	// drift is the least squares slope of the offset over server time
+	// both axes are in seconds, so the slope is dimensionless and scaled to parts per million
+	var sumX, sumY, sumXY, sumXX float64
+	for _, heartbeat := range deviceHeartbeats {
+		x := heartbeat.ReceivedAt.Sub(first).Seconds()
+		y := heartbeat.ReportedAt.Sub(heartbeat.ReceivedAt).Seconds()
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(deviceHeartbeats))
+	denominator := n*sumXX - sumX*sumX
+	if denominator > 0 {
+		drift := (n*sumXY - sumX*sumY) / denominator * 1e6
+		estimate.DriftPPM = &drift
+	}
+	return estimate
+}
+
+// countSkewedRecords returns how many heartbeats and stats were marked as skewed
+func countSkewedRecords(deviceHeartbeats []Heartbeat, deviceStats []DeviceStats) int {
+	count := 0
+	for _, heartbeat := range deviceHeartbeats {
+		if heartbeat.Skewed {
+			count++
+		}
+	}
+	for _, stats := range deviceStats {
+		if stats.Skewed {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEstimateClockSkipsServerTimed(t *testing.T) {
+	// the device clock runs 30s ahead, a pong between every heartbeat is stamped with the server's
+	var heartbeats []Heartbeat
+	for i := range 20 {
+		receivedAt := simulatorStart.Add(time.Duration(i) * time.Minute)
+		heartbeats = append(heartbeats,
+			Heartbeat{SentAt: receivedAt.Add(30 * time.Second), ReportedAt: receivedAt.Add(30 * time.Second), ReceivedAt: receivedAt},
+			Heartbeat{SentAt: receivedAt.Add(30 * time.Second), ReportedAt: receivedAt.Add(30 * time.Second), ReceivedAt: receivedAt.Add(30 * time.Second), ServerTimed: true},
+		)
+	}
+
+	estimate := estimateClock(heartbeats, time.Minute)
+	if estimate.Offset != 30*time.Second || estimate.Samples != 20 {
+		t.Errorf("got offset %s from %d samples, want 30s from 20", estimate.Offset, estimate.Samples)
+	}
+	if estimate.DriftPPM == nil || *estimate.DriftPPM != 0 {
+		t.Errorf("got drift %v, want 0", estimate.DriftPPM)
+	}
+
+	// only the server's own heartbeats, nothing to estimate from
+	if estimate := estimateClock(heartbeats[1:2], time.Minute); estimate.Samples != 0 || estimate.Offset != 0 {
+		t.Errorf("got offset %s from %d samples, want nothing", estimate.Offset, estimate.Samples)
+	}
+}
+
+func TestAddServerHeartbeat(t *testing.T) {
+	tests := []struct {
+		name string
+		// how long ago the device's last heartbeat was received, none when zero
+		lastHeartbeat time.Duration
+		want          int
+	}{
+		{name: "no heartbeats yet", want: 1},
+		{name: "device heartbeat this interval", lastHeartbeat: 30 * time.Second, want: 1},
+		{name: "device heartbeat last interval", lastHeartbeat: 90 * time.Second, want: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewServer(map[string]Device{"cam1": {ID: "cam1"}}, map[string][]Heartbeat{"cam1": {}}, map[string][]DeviceStats{"cam1": {}}, Options{})
+			if test.lastHeartbeat > 0 {
+				receivedAt := time.Now().UTC().Add(-test.lastHeartbeat)
+				server.deviceHeartbeatMap["cam1"] = []Heartbeat{{SentAt: receivedAt, ReportedAt: receivedAt, ReceivedAt: receivedAt}}
+			}
+
+			if err := server.addServerHeartbeat(context.Background(), "cam1", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			heartbeats := server.deviceHeartbeatMap["cam1"]
+			if len(heartbeats) != test.want {
+				t.Fatalf("got %d heartbeats, want %d", len(heartbeats), test.want)
+			}
+			if added := heartbeats[len(heartbeats)-1]; test.want > 1 || test.lastHeartbeat == 0 {
+				if !added.ServerTimed || !added.SentAt.Equal(added.ReceivedAt) {
+					t.Errorf("got %+v, want a heartbeat timed by the server", added)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown device", func(t *testing.T) {
+		server := NewServer(map[string]Device{}, map[string][]Heartbeat{}, map[string][]DeviceStats{}, Options{})
+		if err := server.addServerHeartbeat(context.Background(), "cam9", time.Minute); err != ErrDeviceNotFound {
+			t.Errorf("got error %v, want %v", err, ErrDeviceNotFound)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauspost/compress/zstd"
+
+	"fleetsy/pkg/api"
+)
+
+// DecompressRequestBody transparently decompresses gzip and zstd request bodies.
+//
+// maxSize caps the decompressed size of a body so a small compressed payload can't expand into
+// something that exhausts memory.  The body is read here, so the handlers only ever see a body
+// that decompressed cleanly and fits.  A corrupt body is a 400, an unknown encoding a 415 and a
+// body over the cap a 413.
+func DecompressRequestBody(maxSize int64) api.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, code, err := readRequestBody(w, r, maxSize)
+			if err != nil {
+				recordError(r, err)
+				// the spec only allows 204, 404 and 500 for the original device endpoints
+				if legacyDeviceRoute(r) {
+					http.Error(w, "Invalid request body", http.StatusInternalServerError)
+					return
+				}
+				writeError(w, code, err.Error())
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.Header.Del("Content-Encoding")
+			r.Header.Set("Content-Length", fmt.Sprint(len(body)))
+			r.ContentLength = int64(len(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readRequestBody reads and decompresses a request body, returning the status code to respond
+// with when it can't
+func readRequestBody(w http.ResponseWriter, r *http.Request, maxSize int64) ([]byte, int, error) {
+	// the compressed body can't be bigger than the decompressed one is allowed to be either
+	compressed := http.MaxBytesReader(w, r.Body, maxSize)
+	var reader io.Reader = compressed
+
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(compressed)
+		if err != nil {
+			return nil, bodyErrorCode(err), fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "zstd":
+		// every frame needs at least a 1KB window, the read below still enforces maxSize
+		zstdReader, err := zstd.NewReader(compressed,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(uint64(max(maxSize, zstd.MinWindowSize))),
+		)
+		if err != nil {
+			return nil, bodyErrorCode(err), fmt.Errorf("invalid zstd body: %w", err)
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content encoding %q, use gzip or zstd", encoding)
+	}
+
+	// one byte past the cap is enough to know the body is too big
+	body, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, bodyErrorCode(err), fmt.Errorf("invalid request body: %w", err)
+	}
+	if int64(len(body)) > maxSize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", maxSize)
+	}
+	return body, 0, nil
+}
+
+// bodyErrorCode returns the status code for an error reading a request body, 413 when the
+// body went over the cap and 400 when it's corrupt
+func bodyErrorCode(err error) int {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) || errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// legacyDeviceRoute reports whether the request is for one of the original device endpoints,
+// POST heartbeat and stats, whose spec only allows 204, 404 and 500
+func legacyDeviceRoute(r *http.Request) bool {
+	routeContext := chi.RouteContext(r.Context())
+	if r.Method != http.MethodPost || routeContext == nil {
+		return false
+	}
+	route := routeContext.RoutePattern()
+	return strings.HasSuffix(route, "/devices/{device_id}/heartbeat") || strings.HasSuffix(route, "/devices/{device_id}/stats")
+}
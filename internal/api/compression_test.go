@@ -0,0 +1,94 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipped(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(body)
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return compressed.Bytes()
+}
+
+func zstded(t *testing.T, body []byte) []byte {
+	t.Helper()
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(body, nil)
+}
+
+func TestDecompressRequestBody(t *testing.T) {
+	const maxSize = 64
+	small := []byte(`{"sent_at":"2024-01-01T00:00:00Z"}`)
+	// compresses to a few bytes, well under the cap, but expands far past it
+	big := bytes.Repeat([]byte("0"), 10*maxSize)
+
+	// the handlers echo the body they were given
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}
+	router := chi.NewRouter()
+	router.Post("/devices/{device_id}/heartbeat", DecompressRequestBody(maxSize)(http.HandlerFunc(echo)).ServeHTTP)
+	router.Put("/slos/{slo}", DecompressRequestBody(maxSize)(http.HandlerFunc(echo)).ServeHTTP)
+
+	tests := []struct {
+		name     string
+		path     string
+		encoding string
+		body     []byte
+		code     int
+	}{
+		{name: "plain", path: "/slos/s1", body: small, code: http.StatusOK},
+		{name: "gzip", path: "/slos/s1", encoding: "gzip", body: gzipped(t, small), code: http.StatusOK},
+		{name: "zstd", path: "/slos/s1", encoding: "zstd", body: zstded(t, small), code: http.StatusOK},
+		{name: "plain over the cap", path: "/slos/s1", body: big, code: http.StatusRequestEntityTooLarge},
+		{name: "gzip expanding past the cap", path: "/slos/s1", encoding: "gzip", body: gzipped(t, big), code: http.StatusRequestEntityTooLarge},
+		{name: "zstd expanding past the cap", path: "/slos/s1", encoding: "zstd", body: zstded(t, big), code: http.StatusRequestEntityTooLarge},
+		{name: "corrupt gzip", path: "/slos/s1", encoding: "gzip", body: []byte("not gzip at all"), code: http.StatusBadRequest},
+		{name: "truncated gzip", path: "/slos/s1", encoding: "gzip", body: gzipped(t, small)[:20], code: http.StatusBadRequest},
+		{name: "corrupt zstd", path: "/slos/s1", encoding: "zstd", body: []byte("not zstd at all"), code: http.StatusBadRequest},
+		{name: "unknown encoding", path: "/slos/s1", encoding: "br", body: small, code: http.StatusUnsupportedMediaType},
+		// the heartbeat spec only allows 204, 404 and 500
+		{name: "heartbeat gzip", path: "/devices/cam1/heartbeat", encoding: "gzip", body: gzipped(t, small), code: http.StatusOK},
+		{name: "heartbeat expanding past the cap", path: "/devices/cam1/heartbeat", encoding: "gzip", body: gzipped(t, big), code: http.StatusInternalServerError},
+		{name: "heartbeat unknown encoding", path: "/devices/cam1/heartbeat", encoding: "br", body: small, code: http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := http.MethodPut
+			if strings.HasSuffix(test.path, "/heartbeat") {
+				method = http.MethodPost
+			}
+			r := httptest.NewRequest(method, test.path, bytes.NewReader(test.body))
+			if test.encoding != "" {
+				r.Header.Set("Content-Encoding", test.encoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != test.code {
+				t.Fatalf("got %d (%s), want %d", w.Code, w.Body, test.code)
+			}
+			if test.code == http.StatusOK && !bytes.Equal(w.Body.Bytes(), small) {
+				t.Errorf("handler got %q, want %q", w.Body, small)
+			}
+		})
+	}
+}
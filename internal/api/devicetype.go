@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeviceType describes how one kind of device reports data and how its stats are worked out.
+//
+// Device types are registered with RegisterDeviceType, usually from an init function, under
+// the model name used in devices.csv.  Devices whose model has no registered type are treated
+// as cameras, which is how every device behaved before types existed.
+type DeviceType interface {
+	// HeartbeatInterval is how often a healthy device of this type sends a heartbeat
+	HeartbeatInterval() time.Duration
+
+	// DecodeStats turns the body of a stats message into a record for the device stats array
+	DecodeStats(payload []byte) (DeviceStats, error)
+
+	// ValidateStats checks a decoded record before it is stored
+	ValidateStats(stats DeviceStats) error
+
+	// Stats computes the type specific part of the stats response from the device's history.
+	// Anything that applies to every device, like uptime and clock skew, is filled in by the caller.
+	// Uptime is worked out from HeartbeatInterval using the configured uptime mode.
+	Stats(heartbeats []Heartbeat, stats []DeviceStats) StatsGet
+}
+
+// the model used for devices whose model has no registered type
+const fallbackDeviceType = "camera"
+
+var (
+	deviceTypesMutex sync.RWMutex
+	deviceTypes      = make(map[string]DeviceType)
+)
+
+// RegisterDeviceType makes a device type available for devices of the given model.
+// Like database/sql drivers, registering the same model twice is a programming error and panics.
+func RegisterDeviceType(model string, deviceType DeviceType) {
+	deviceTypesMutex.Lock()
+	defer deviceTypesMutex.Unlock()
+
+	if deviceType == nil {
+		panic("api: RegisterDeviceType device type is nil")
+	}
+	if _, dup := deviceTypes[model]; dup {
+		panic(fmt.Sprintf("api: RegisterDeviceType called twice for model %q", model))
+	}
+	deviceTypes[model] = deviceType
+}
+
+// DeviceTypes returns the sorted list of models with a registered device type
+func DeviceTypes() []string {
+	deviceTypesMutex.RLock()
+	defer deviceTypesMutex.RUnlock()
+
+	models := make([]string, 0, len(deviceTypes))
+	for model := range deviceTypes {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// deviceTypeFor returns the device type for a model, falling back to a camera
+func deviceTypeFor(model string) DeviceType {
+	deviceTypesMutex.RLock()
+	defer deviceTypesMutex.RUnlock()
+
+	if deviceType, found := deviceTypes[model]; found {
+		return deviceType
+	}
+	return deviceTypes[fallbackDeviceType]
+}
+
+// decodeStats decodes and validates a stats payload using the device's type
+func (s *Server) decodeStats(deviceId string, payload []byte) (DeviceStats, error) {
+	device, found := s.device(deviceId)
+	if !found {
+		return DeviceStats{}, ErrDeviceNotFound
+	}
+
+	deviceType := deviceTypeFor(device.Model)
+	stats, err := deviceType.DecodeStats(payload)
+	if err != nil {
+		return DeviceStats{}, err
+	}
+	if err := deviceType.ValidateStats(stats); err != nil {
+		return DeviceStats{}, err
+	}
+	return stats, nil
+}
+
+// validateStats checks a record that was decoded elsewhere, eg from gRPC, using the device's type
+func (s *Server) validateStats(deviceId string, stats DeviceStats) error {
+	device, found := s.device(deviceId)
+	if !found {
+		return ErrDeviceNotFound
+	}
+	return deviceTypeFor(device.Model).ValidateStats(stats)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+func init() {
+	RegisterDeviceType("camera", cameraType{})
+}
+
+// cameraType is the original fleetsy device: a heartbeat every minute and a stats
+// record for every video it uploads
+type cameraType struct{}
+
+func (cameraType) HeartbeatInterval() time.Duration {
+	return time.Minute
+}
+
+func (cameraType) DecodeStats(payload []byte) (DeviceStats, error) {
+	var newData StatsPost
+	if err := json.Unmarshal(payload, &newData); err != nil {
+		return DeviceStats{}, err
+	}
+
+	// parse the timestamp
+	sentAt, err := time.Parse(time.RFC3339, newData.SentAt)
+	if err != nil {
+		return DeviceStats{}, err
+	}
+	return newData.deviceStats(sentAt), nil
+}
+
+func (cameraType) ValidateStats(stats DeviceStats) error {
+	if stats.UploadTime < 0 {
+		return errors.New("upload_time can't be negative")
+	}
+	if stats.BytesUploaded < 0 {
+		return errors.New("bytes_uploaded can't be negative")
+	}
+	return nil
+}
+
+func (c cameraType) Stats(heartbeats []Heartbeat, stats []DeviceStats) StatsGet {
+	return StatsGet{
+		AvgUploadTime: calculateAvgUploadTime(stats),
+		Throughput:    calculateThroughput(stats),
+		FailureRate:   calculateFailureRate(stats),
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+func init() {
+	RegisterDeviceType("doorbell", doorbellType{})
+}
+
+// events a doorbell uploads a clip for
+const (
+	doorbellEventRing   = "ring"
+	doorbellEventMotion = "motion"
+)
+
+// doorbellType is a battery powered camera that only uploads when someone rings or walks past.
+// It reports the same upload details as a camera plus what triggered the upload and its battery level.
+type doorbellType struct {
+	cameraType
+}
+
+// struct for the incoming doorbell stats
+type doorbellStatsPost struct {
+	StatsPost
+	Event        string   `json:"event"`         // ring or motion
+	BatteryLevel *float64 `json:"battery_level"` // percentage, optional
+}
+
+func (doorbellType) DecodeStats(payload []byte) (DeviceStats, error) {
+	var newData doorbellStatsPost
+	if err := json.Unmarshal(payload, &newData); err != nil {
+		return DeviceStats{}, err
+	}
+
+	// parse the timestamp
+	sentAt, err := time.Parse(time.RFC3339, newData.SentAt)
+	if err != nil {
+		return DeviceStats{}, err
+	}
+
+	stats := newData.deviceStats(sentAt)
+	stats.Extra = map[string]any{"event": newData.Event}
+	if newData.BatteryLevel != nil {
+		stats.Extra["battery_level"] = *newData.BatteryLevel
+	}
+	return stats, nil
+}
+
+func (d doorbellType) ValidateStats(stats DeviceStats) error {
+	if err := d.cameraType.ValidateStats(stats); err != nil {
+		return err
+	}
+
+	event, _ := stats.Extra["event"].(string)
+	if event != doorbellEventRing && event != doorbellEventMotion {
+		return errors.New("event must be ring or motion")
+	}
+	if battery, found := stats.Extra["battery_level"].(float64); found && (battery < 0 || battery > 100) {
+		return errors.New("battery_level must be between 0 and 100")
+	}
+	return nil
+}
+
+func (d doorbellType) Stats(heartbeats []Heartbeat, stats []DeviceStats) StatsGet {
+	response := d.cameraType.Stats(heartbeats, stats)
+
+	rings, motion := 0, 0
+	var battery *float64
+	for _, record := range stats {
+		switch record.Extra["event"] {
+		case doorbellEventRing:
+			rings++
+		case doorbellEventMotion:
+			motion++
+		}
+		// the records are in chronological order so the last one wins
+		if level, found := record.Extra["battery_level"].(float64); found {
+			battery = &level
+		}
+	}
+
+	response.Details = map[string]float64{
+		"rings":         float64(rings),
+		"motion_events": float64(motion),
+	}
+	if battery != nil {
+		response.Details["battery_level"] = *battery
+	}
+	return response
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+func init() {
+	RegisterDeviceType("sensor", sensorType{})
+}
+
+// sensorType is an environmental sensor.  It saves power by only sending a heartbeat
+// every five minutes and its stats are readings rather than uploads.
+type sensorType struct{}
+
+// struct for the incoming sensor readings
+type sensorStatsPost struct {
+	SentAt      string   `json:"sent_at"`
+	Temperature *float64 `json:"temperature"` // celsius
+	Humidity    *float64 `json:"humidity"`    // relative humidity percentage, optional
+}
+
+func (sensorType) HeartbeatInterval() time.Duration {
+	return 5 * time.Minute
+}
+
+func (sensorType) DecodeStats(payload []byte) (DeviceStats, error) {
+	var newData sensorStatsPost
+	if err := json.Unmarshal(payload, &newData); err != nil {
+		return DeviceStats{}, err
+	}
+
+	// parse the timestamp
+	sentAt, err := time.Parse(time.RFC3339, newData.SentAt)
+	if err != nil {
+		return DeviceStats{}, err
+	}
+
+	stats := DeviceStats{SentAt: sentAt, Extra: map[string]any{}}
+	if newData.Temperature != nil {
+		stats.Extra["temperature"] = *newData.Temperature
+	}
+	if newData.Humidity != nil {
+		stats.Extra["humidity"] = *newData.Humidity
+	}
+	return stats, nil
+}
+
+func (sensorType) ValidateStats(stats DeviceStats) error {
+	if _, found := stats.Extra["temperature"].(float64); !found {
+		return errors.New("temperature is required")
+	}
+	if humidity, found := stats.Extra["humidity"].(float64); found && (humidity < 0 || humidity > 100) {
+		return errors.New("humidity must be between 0 and 100")
+	}
+	return nil
+}
+
+func (t sensorType) Stats(heartbeats []Heartbeat, stats []DeviceStats) StatsGet {
+	response := StatsGet{}
+	if len(stats) == 0 {
+		return response
+	}
+
+	var totalTemperature, totalHumidity float64
+	var minTemperature, maxTemperature float64
+	temperatures, humidities := 0, 0
+	for _, record := range stats {
+		if temperature, found := record.Extra["temperature"].(float64); found {
+			if temperatures == 0 {
+				minTemperature, maxTemperature = temperature, temperature
+			}
+			minTemperature = min(minTemperature, temperature)
+			maxTemperature = max(maxTemperature, temperature)
+			totalTemperature += temperature
+			temperatures++
+		}
+		if humidity, found := record.Extra["humidity"].(float64); found {
+			totalHumidity += humidity
+			humidities++
+		}
+	}
+
+	response.Details = map[string]float64{}
+	if temperatures > 0 {
+		response.Details["avg_temperature"] = totalTemperature / float64(temperatures)
+		response.Details["min_temperature"] = minTemperature
+		response.Details["max_temperature"] = maxTemperature
+	}
+	if humidities > 0 {
+		response.Details["avg_humidity"] = totalHumidity / float64(humidities)
+	}
+	return response
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"time"
+
+	"fleetsy/internal/sketch"
+)
+
+// upload time percentiles are accurate to within 1% of the true value
+const uploadTimeAccuracy = 0.01
+
+// the upper bounds of the upload time histogram buckets
+var uploadTimeBuckets = []time.Duration{
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+}
+
+// UploadTimeDistribution describes the spread of upload times, all durations are strings like avg_upload_time
+type UploadTimeDistribution struct {
+	Count     uint64            `json:"count"`
+	Min       string            `json:"min"`
+	Max       string            `json:"max"`
+	P50       string            `json:"p50"`
+	P90       string            `json:"p90"`
+	P95       string            `json:"p95"`
+	P99       string            `json:"p99"`
+	StdDev    string            `json:"std_dev"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// HistogramBucket counts the uploads that took at most LE, so the counts are cumulative
+type HistogramBucket struct {
+	LE    string `json:"le"` // "+Inf" for the last bucket
+	Count uint64 `json:"count"`
+}
+
+// uploadTimeDistribution summarizes an upload time sketch, nil if it's empty
+func uploadTimeDistribution(uploadTimes *sketch.Sketch) *UploadTimeDistribution {
+	if uploadTimes == nil || uploadTimes.Count() == 0 {
+		return nil
+	}
+
+	// the sketch works in nanoseconds, same as upload_time
+	duration := func(nanoseconds float64) string {
+		return time.Duration(nanoseconds).String()
+	}
+
+	distribution := &UploadTimeDistribution{
+		Count:  uploadTimes.Count(),
+		Min:    duration(uploadTimes.Min()),
+		Max:    duration(uploadTimes.Max()),
+		P50:    duration(uploadTimes.Quantile(0.50)),
+		P90:    duration(uploadTimes.Quantile(0.90)),
+		P95:    duration(uploadTimes.Quantile(0.95)),
+		P99:    duration(uploadTimes.Quantile(0.99)),
+		StdDev: duration(uploadTimes.StdDev()),
+	}
+	for _, bucket := range uploadTimeBuckets {
+		distribution.Histogram = append(distribution.Histogram, HistogramBucket{
+			LE:    bucket.String(),
+			Count: uploadTimes.CountBelow(float64(bucket)),
+		})
+	}
+	distribution.Histogram = append(distribution.Histogram, HistogramBucket{LE: "+Inf", Count: uploadTimes.Count()})
+	return distribution
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a duration in the forms dashboards can use without parsing Go duration strings
+type Duration struct {
+	Nanoseconds  int64   `json:"ns"`
+	Milliseconds float64 `json:"ms"`
+	ISO8601      string  `json:"iso8601"`
+}
+
+// newDuration converts a duration into every numeric form
+func newDuration(d time.Duration) Duration {
+	return Duration{
+		Nanoseconds:  int64(d),
+		Milliseconds: float64(d) / float64(time.Millisecond),
+		ISO8601:      formatISO8601Duration(d),
+	}
+}
+
+// numericDurations collects every duration string in a stats response, keyed by field name.
+// The v1 strings come from time.Duration.String(), which round trips exactly through
+// time.ParseDuration, so the numbers always agree with the strings.
+func numericDurations(stats StatsGet) map[string]Duration {
+	durations := make(map[string]Duration)
+	add := func(name, value string) {
+		if d, err := time.ParseDuration(value); err == nil {
+			durations[name] = newDuration(d)
+		}
+	}
+
+	add("avg_upload_time", stats.AvgUploadTime)
+	add("clock_offset", stats.ClockOffset)
+	if distribution := stats.UploadTimeDistribution; distribution != nil {
+		add("upload_time_distribution.min", distribution.Min)
+		add("upload_time_distribution.max", distribution.Max)
+		add("upload_time_distribution.p50", distribution.P50)
+		add("upload_time_distribution.p90", distribution.P90)
+		add("upload_time_distribution.p95", distribution.P95)
+		add("upload_time_distribution.p99", distribution.P99)
+		add("upload_time_distribution.std_dev", distribution.StdDev)
+	}
+	return durations
+}
+
+// formatISO8601Duration formats a duration like PT3M21.858747766S.  Only hours, minutes and
+// seconds are used since a day isn't always 24 hours, and negative durations get a leading minus.
+func formatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	if d < 0 {
+		b.WriteString("-")
+		d = -d
+	}
+	b.WriteString("PT")
+
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	if hours > 0 {
+		b.WriteString(strconv.FormatInt(int64(hours), 10) + "H")
+	}
+	if minutes > 0 {
+		b.WriteString(strconv.FormatInt(int64(minutes), 10) + "M")
+	}
+	if d > 0 {
+		// whole seconds, then the fraction without trailing zeros
+		seconds := strconv.FormatInt(int64(d/time.Second), 10)
+		if fraction := d % time.Second; fraction > 0 {
+			seconds += "." + strings.TrimRight(strconv.FormatInt(int64(fraction)+int64(time.Second), 10)[1:], "0")
+		}
+		b.WriteString(seconds + "S")
+	}
+	return b.String()
+}
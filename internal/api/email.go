@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// the templates used when a route doesn't give its own
+const (
+	defaultEmailSubject = `[{{.Event}}] {{.Alert.Rule}} on {{.Alert.DeviceID}}`
+	defaultEmailBody    = `{{.Alert.Rule}} is {{.Alert.State}} on {{.Alert.DeviceID}}
+{{with .Alert.Value}}
+Value: {{.}}{{end}}
+Active since: {{.Alert.ActiveSince}}
+{{range $name, $value := .Alert.Labels}}
+{{$name}}: {{$value}}{{end}}
+`
+)
+
+// struct for the email route output
+type EmailOutput struct {
+	Addr     string   `json:"addr"` // host:port of the SMTP server
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"` // only read from requests, never returned
+	Subject  string   `json:"subject,omitempty"`  // text/template
+	Body     string   `json:"body,omitempty"`     // text/template
+
+	// send in the clear when the server doesn't offer STARTTLS, otherwise the email fails
+	AllowPlaintext bool `json:"allow_plaintext,omitempty"`
+
+	password string
+	subject  *template.Template
+	body     *template.Template
+}
+
+// what the email templates are executed with
+type emailData struct {
+	Event string
+	Alert Alert
+}
+
+// the rendered email, queued as the notification payload
+type emailMessage struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// validate checks the addresses and compiles the templates
+func (output *EmailOutput) validate() error {
+	if _, _, err := net.SplitHostPort(output.Addr); err != nil {
+		return errors.New("email addr must be host:port, eg localhost:25")
+	}
+	if _, err := mail.ParseAddress(output.From); err != nil {
+		return fmt.Errorf("email from is invalid: %w", err)
+	}
+	if len(output.To) == 0 {
+		return errors.New("email needs at least one to address")
+	}
+	for _, to := range output.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("email to %q is invalid: %w", to, err)
+		}
+	}
+
+	// keep the password out of the registry responses
+	output.password = output.Password
+	output.Password = ""
+
+	var err error
+	if output.subject, err = parseEmailTemplate("subject", output.Subject, defaultEmailSubject); err != nil {
+		return err
+	}
+	if output.body, err = parseEmailTemplate("body", output.Body, defaultEmailBody); err != nil {
+		return err
+	}
+	// catch templates that parse but use fields that don't exist
+	_, err = output.payload(EventAlertFiring, sampleAlert(time.Now().UTC()))
+	return err
+}
+
+// parseEmailTemplate parses a template, or the default when it's empty
+func parseEmailTemplate(name, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	parsed, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("email %s template is invalid: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// payload renders the email for an alert
+func (output *EmailOutput) payload(event string, alert Alert) ([]byte, error) {
+	data := emailData{Event: event, Alert: alert}
+	var subject, body bytes.Buffer
+	if err := output.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("email subject template failed: %w", err)
+	}
+	if err := output.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("email body template failed: %w", err)
+	}
+	// headers can't span lines
+	message := emailMessage{Subject: strings.Join(strings.Fields(subject.String()), " "), Body: body.String()}
+	return json.Marshal(message)
+}
+
+// sendEmail sends a rendered email through the route's SMTP server.  The connection is upgraded
+// with STARTTLS before the credentials or the email are sent, and a server that doesn't offer it
+// is an error unless the route allows plaintext.
+func (s *Server) sendEmail(ctx context.Context, output *EmailOutput, payload []byte) error {
+	var message emailMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: s.options.WebhookTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", output.Addr)
+	if err != nil {
+		return err
+	}
+	// net/smtp has no timeouts of its own
+	conn.SetDeadline(time.Now().Add(s.options.WebhookTimeout))
+	host, _, _ := net.SplitHostPort(output.Addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	} else if !output.AllowPlaintext {
+		return errors.New("smtp server doesn't offer STARTTLS, set allow_plaintext to send without TLS")
+	}
+	if output.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", output.Username, output.password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(output.From); err != nil {
+		return err
+	}
+	for _, to := range output.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	headers := []string{
+		"From: " + output.From,
+		"To: " + strings.Join(output.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+	body := strings.ReplaceAll(message.Body, "\n", "\r\n")
+	if _, err := data.Write([]byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body)); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is an SMTP server that accepts a single connection and records the commands it's sent.
+// STARTTLS is refused if it's offered, so nothing after it should be sent.
+type fakeSMTP struct {
+	listener net.Listener
+	commands chan []string
+	message  chan string
+}
+
+func newFakeSMTP(t *testing.T, offerStartTLS bool) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	fake := &fakeSMTP{listener: listener, commands: make(chan []string, 1), message: make(chan string, 1)}
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		var commands []string
+		defer func() { fake.commands <- commands }()
+
+		text.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.Fields(line + " ")[0])
+			commands = append(commands, verb)
+			switch verb {
+			case "EHLO":
+				text.PrintfLine("250-fake")
+				if offerStartTLS {
+					text.PrintfLine("250-STARTTLS")
+				}
+				text.PrintfLine("250 AUTH PLAIN")
+			case "STARTTLS":
+				text.PrintfLine("454 TLS not available")
+			case "AUTH":
+				text.PrintfLine("235 ok")
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				message, err := text.ReadDotLines()
+				if err != nil {
+					return
+				}
+				fake.message <- strings.Join(message, "\n")
+				text.PrintfLine("250 queued")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				return
+			default:
+				text.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return fake
+}
+
+func TestSendEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		offerStartTLS  bool
+		allowPlaintext bool
+		username       string
+		wantErr        bool
+		// commands the server should have seen
+		want []string
+	}{
+		// the credentials and the email mustn't go out in the clear
+		{name: "no STARTTLS", username: "fleetsy", wantErr: true, want: []string{"EHLO"}},
+		{name: "no STARTTLS allowed", allowPlaintext: true, want: []string{"EHLO", "MAIL", "RCPT", "RCPT", "DATA", "QUIT"}},
+		{name: "no STARTTLS allowed with auth", allowPlaintext: true, username: "fleetsy", want: []string{"EHLO", "AUTH", "MAIL", "RCPT", "RCPT", "DATA", "QUIT"}},
+		// STARTTLS is always used when it's offered, and a failure stops the email
+		{name: "STARTTLS fails", offerStartTLS: true, allowPlaintext: true, username: "fleetsy", wantErr: true, want: []string{"EHLO", "STARTTLS"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newFakeSMTP(t, test.offerStartTLS)
+			server := NewServer(map[string]Device{}, map[string][]Heartbeat{}, map[string][]DeviceStats{}, Options{WebhookTimeout: 5 * time.Second})
+			output := &EmailOutput{
+				Addr: fake.listener.Addr().String(), From: "fleetsy@example.com", To: []string{"oncall@example.com", "team@example.com"},
+				Username: test.username, Password: "secret", AllowPlaintext: test.allowPlaintext,
+			}
+			if err := output.validate(); err != nil {
+				t.Fatal(err)
+			}
+			payload, err := output.payload(EventAlertFiring, sampleAlert(time.Now().UTC()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = server.sendEmail(context.Background(), output, payload)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want an error %v", err, test.wantErr)
+			}
+			// the client closes the connection once it's done, so the commands are all in
+			if got := <-fake.commands; strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("got commands %v, want %v", got, test.want)
+			}
+			if test.wantErr {
+				return
+			}
+			message := <-fake.message
+			if !strings.Contains(message, "Subject: [alert.firing] test on test") || !strings.Contains(message, "To: oncall@example.com, team@example.com") {
+				t.Errorf("got message %q", message)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"fleetsy/pkg/api"
+)
+
+// health states a device can be in, worked out from its uptime
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+	HealthNoData    = "no_data" // no heartbeats in the window
+)
+
+// uptime thresholds for the health states
+const (
+	healthyUptime  = 99.0
+	degradedUptime = 95.0
+)
+
+// paging limits for the fleet rows
+const (
+	defaultFleetLimit = 50
+	maxFleetLimit     = 1000
+)
+
+// columns the fleet rows can be sorted by
+var fleetSortColumns = []string{"device_id", "uptime", "avg_upload_time", "heartbeats", "uploads", "last_heartbeat"}
+
+// response struct for the fleet stats GET requests
+type FleetStatsGet struct {
+	Devices       int            `json:"devices"`
+	Uptime        float32        `json:"uptime"` // mean of the device uptimes
+	AvgUploadTime string         `json:"avg_upload_time"`
+	Health        map[string]int `json:"health"`
+	Total         int            `json:"total"` // rows matching the filters before paging
+	Rows          []FleetRow     `json:"rows"`
+}
+
+// a single device in the fleet stats response
+type FleetRow struct {
+	DeviceID      string     `json:"device_id"`
+	Model         string     `json:"model"`
+	Group         string     `json:"group,omitempty"`
+	Health        string     `json:"health"`
+	Uptime        float32    `json:"uptime"`
+	AvgUploadTime string     `json:"avg_upload_time"`
+	Heartbeats    int        `json:"heartbeats"`
+	Uploads       int        `json:"uploads"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+
+	// kept for sorting so the duration string doesn't need parsing
+	avgUploadTime time.Duration
+}
+
+// options for the fleet stats query
+type fleetQuery struct {
+	sort   string
+	desc   bool
+	limit  int
+	offset int
+	health string
+	group  string
+	from   *time.Time
+	to     *time.Time
+}
+
+// (GET /fleet/stats)
+func (s *Server) GetFleetStats(w http.ResponseWriter, r *http.Request, params api.GetFleetStatsParams) {
+	query, err := newFleetQuery(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, s.fleetStats(r.Context(), query))
+}
+
+// newFleetQuery validates the query parameters and fills in the defaults
+func newFleetQuery(params api.GetFleetStatsParams) (fleetQuery, error) {
+	query := fleetQuery{
+		sort:  "device_id",
+		limit: defaultFleetLimit,
+		from:  params.From,
+		to:    params.To,
+	}
+
+	if params.Sort != nil {
+		if !slices.Contains(fleetSortColumns, *params.Sort) {
+			return fleetQuery{}, fmt.Errorf("sort must be one of %v", fleetSortColumns)
+		}
+		query.sort = *params.Sort
+	}
+	if params.Order != nil {
+		switch *params.Order {
+		case "asc":
+		case "desc":
+			query.desc = true
+		default:
+			return fleetQuery{}, fmt.Errorf("order must be asc or desc")
+		}
+	}
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxFleetLimit {
+			return fleetQuery{}, fmt.Errorf("limit must be between 1 and %d", maxFleetLimit)
+		}
+		query.limit = *params.Limit
+	}
+	if params.Offset != nil {
+		if *params.Offset < 0 {
+			return fleetQuery{}, fmt.Errorf("offset can't be negative")
+		}
+		query.offset = *params.Offset
+	}
+	if params.Health != nil {
+		switch *params.Health {
+		case HealthHealthy, HealthDegraded, HealthUnhealthy, HealthNoData:
+			query.health = *params.Health
+		default:
+			return fleetQuery{}, fmt.Errorf("health must be one of %s, %s, %s or %s", HealthHealthy, HealthDegraded, HealthUnhealthy, HealthNoData)
+		}
+	}
+	if params.Group != nil {
+		query.group = *params.Group
+	}
+	if query.from != nil && query.to != nil && !query.from.Before(*query.to) {
+		return fleetQuery{}, fmt.Errorf("to must be after from")
+	}
+	return query, nil
+}
+
+// fleetStats works out the fleet summary and a row for every device while holding the
+// read lock once, instead of the caller making one locked stats request per device
+func (s *Server) fleetStats(ctx context.Context, query fleetQuery) FleetStatsGet {
+	ctx, span := startStoreSpan(ctx, "fleet_stats", attribute.String("fleetsy.group", query.group))
+	defer span.End()
+
+	response := FleetStatsGet{
+		Health: map[string]int{HealthHealthy: 0, HealthDegraded: 0, HealthUnhealthy: 0, HealthNoData: 0},
+		Rows:   []FleetRow{},
+	}
+	var rows []FleetRow
+	var totalUptime float32 = 0
+	var totalUploadTime float64 = 0
+	var totalUploads int = 0
+
+	// lock the mutex for reading
+	s.rlockDevices(ctx)
+	for deviceId, device := range s.devices {
+		if query.group != "" && device.Group != query.group {
+			continue
+		}
+
+		row, uploadTime := s.fleetRow(deviceId, device, query.from, query.to)
+		response.Devices++
+		response.Health[row.Health]++
+		totalUptime += row.Uptime
+		totalUploadTime += uploadTime
+		totalUploads += row.Uploads
+
+		if query.health == "" || row.Health == query.health {
+			rows = append(rows, row)
+		}
+	}
+	s.deviceMutex.RUnlock()
+
+	if response.Devices > 0 {
+		response.Uptime = totalUptime / float32(response.Devices)
+	}
+	if totalUploads > 0 {
+		response.AvgUploadTime = time.Duration(totalUploadTime / float64(totalUploads)).String()
+	}
+
+	// sorting and paging happen after the lock is released
+	sortFleetRows(rows, query.sort, query.desc)
+	response.Total = len(rows)
+	if query.offset < len(rows) {
+		end := min(query.offset+query.limit, len(rows))
+		response.Rows = rows[query.offset:end]
+	}
+	return response
+}
+
+// fleetRow works out a device's row, along with the total of its upload times so the
+// fleet average can be weighted by uploads.  The caller must hold the read lock.
+func (s *Server) fleetRow(deviceId string, device Device, from, to *time.Time) (FleetRow, float64) {
+	row := FleetRow{
+		DeviceID: deviceId,
+		Model:    device.Model,
+		Group:    device.Group,
+	}
+	inWindow := func(t time.Time) bool {
+		return (from == nil || !t.Before(*from)) && (to == nil || t.Before(*to))
+	}
+
+	// only copy the heartbeats when there's a window to cut them down to
+	heartbeats := s.deviceHeartbeatMap[deviceId]
+	if from != nil || to != nil {
+		heartbeats = slices.DeleteFunc(slices.Clone(heartbeats), func(heartbeat Heartbeat) bool {
+			return !inWindow(heartbeat.SentAt)
+		})
+	}
+	row.Heartbeats = len(heartbeats)
+	if row.Heartbeats > 0 {
+		// assuming that all heartbeats were received in chronological order
+		last := heartbeats[len(heartbeats)-1].SentAt
+		row.LastHeartbeat = &last
+	}
+	row.Uptime = s.deviceUptime(device, heartbeats, from, to)
+
+	// without a window the device's sketch already knows the mean, so skip the history
+	var totalUploadTime float64
+	if from == nil && to == nil {
+		uploadTimes := s.uploadTimeSketches[deviceId]
+		row.Uploads = int(uploadTimes.Count())
+		totalUploadTime = uploadTimes.Mean() * float64(row.Uploads)
+	} else {
+		for _, stats := range s.deviceStatsMap[deviceId] {
+			if stats.UploadTime <= 0 || !inWindow(stats.SentAt) {
+				continue
+			}
+			row.Uploads++
+			totalUploadTime += float64(stats.UploadTime)
+		}
+	}
+	if row.Uploads > 0 {
+		row.avgUploadTime = time.Duration(totalUploadTime / float64(row.Uploads))
+		row.AvgUploadTime = row.avgUploadTime.String()
+	}
+
+	row.Health = deviceHealth(row.Heartbeats, row.Uptime)
+	return row, totalUploadTime
+}
+
+// deviceHealth buckets a device by its uptime
+func deviceHealth(heartbeats int, uptime float32) string {
+	switch {
+	case heartbeats == 0:
+		return HealthNoData
+	case uptime >= healthyUptime:
+		return HealthHealthy
+	case uptime >= degradedUptime:
+		return HealthDegraded
+	default:
+		return HealthUnhealthy
+	}
+}
+
+// sortFleetRows sorts the rows by a column, ties are broken by device id so paging is stable
+func sortFleetRows(rows []FleetRow, column string, desc bool) {
+	slices.SortFunc(rows, func(a, b FleetRow) int {
+		var c int
+		switch column {
+		case "uptime":
+			c = cmp.Compare(a.Uptime, b.Uptime)
+		case "avg_upload_time":
+			c = cmp.Compare(a.avgUploadTime, b.avgUploadTime)
+		case "heartbeats":
+			c = cmp.Compare(a.Heartbeats, b.Heartbeats)
+		case "uploads":
+			c = cmp.Compare(a.Uploads, b.Uploads)
+		case "last_heartbeat":
+			c = compareLastHeartbeat(a.LastHeartbeat, b.LastHeartbeat)
+		}
+		if desc {
+			c = -c
+		}
+		if c == 0 {
+			c = cmp.Compare(a.DeviceID, b.DeviceID)
+			if desc && column == "device_id" {
+				c = -c
+			}
+		}
+		return c
+	})
+}
+
+// compareLastHeartbeat orders devices that never sent a heartbeat first
+func compareLastHeartbeat(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return a.Compare(*b)
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"fleetsy/internal/sketch"
+	"fleetsy/pkg/api"
+)
+
+// ErrGroupNotFound is returned when no device in devices.csv belongs to a group
+var ErrGroupNotFound = errors.New("group not found")
+
+// what an SLO or alert rule can cover
+const (
+	ScopeDevice = "device"
+	ScopeGroup  = "group"
+)
+
+// response struct for the group stats GET requests
+type GroupStatsGet struct {
+	Group                  string                  `json:"group"`
+	Devices                int                     `json:"devices"`
+	Uptime                 float32                 `json:"uptime"` // mean of the device uptimes
+	AvgUploadTime          string                  `json:"avg_upload_time"`
+	UploadTimeDistribution *UploadTimeDistribution `json:"upload_time_distribution,omitempty"`
+}
+
+// (GET /groups/{group}/stats)
+func (s *Server) GetGroupsGroupStats(w http.ResponseWriter, r *http.Request, group api.GroupPathParam) {
+	response, err := s.groupStats(r.Context(), group)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Group not found")
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// groupStats combines the stats of every device in a group
+func (s *Server) groupStats(ctx context.Context, group string) (GroupStatsGet, error) {
+	ctx, span := startStoreSpan(ctx, "group_stats", attribute.String("fleetsy.group", group))
+	defer span.End()
+
+	// lock the mutex for reading
+	s.rlockDevices(ctx)
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.RUnlock()
+
+	response := GroupStatsGet{Group: group}
+	uploadTimes := sketch.New(uploadTimeAccuracy)
+	var totalUptime float32 = 0
+	for deviceId, device := range s.devices {
+		if device.Group != group {
+			continue
+		}
+		response.Devices++
+
+		// the device sketches merge exactly, so the group percentiles are as accurate as a device's
+		uploadTimes.Merge(s.uploadTimeSketches[deviceId])
+
+		totalUptime += s.deviceUptime(device, s.deviceHeartbeatMap[deviceId], nil, nil)
+	}
+	if response.Devices == 0 {
+		return GroupStatsGet{}, ErrGroupNotFound
+	}
+
+	response.Uptime = totalUptime / float32(response.Devices)
+	if uploadTimes.Count() > 0 {
+		response.AvgUploadTime = time.Duration(uploadTimes.Mean()).String()
+	}
+	response.UploadTimeDistribution = uploadTimeDistribution(uploadTimes)
+	return response, nil
+}
+
+// groupExists reports whether any device belongs to the group
+func (s *Server) groupExists(group string) bool {
+	s.rlockDevices(context.Background())
+	defer s.deviceMutex.RUnlock()
+
+	for _, device := range s.devices {
+		if device.Group == group {
+			return true
+		}
+	}
+	return false
+}
+
+// validateScope checks that a scope names a device or group that exists
+func (s *Server) validateScope(scope, scopeID string) error {
+	switch scope {
+	case ScopeDevice:
+		if _, found := s.device(scopeID); !found {
+			return fmt.Errorf("device %q not found", scopeID)
+		}
+	case ScopeGroup:
+		if !s.groupExists(scopeID) {
+			return fmt.Errorf("group %q not found", scopeID)
+		}
+	default:
+		return fmt.Errorf("scope must be %s or %s", ScopeDevice, ScopeGroup)
+	}
+	return nil
+}
+
+// inScope reports whether a device is covered by a scope
+func inScope(deviceId string, device Device, scope, scopeID string) bool {
+	switch scope {
+	case ScopeDevice:
+		return deviceId == scopeID
+	case ScopeGroup:
+		return device.Group == scopeID
+	}
+	return false
+}
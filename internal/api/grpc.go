@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"fleetsy/pkg/fleetsypb"
+)
+
+// GRPCServer exposes the same operations as the REST API over gRPC.
+// It shares the device store and stats calculations with Server.
+type GRPCServer struct {
+	fleetsypb.UnimplementedFleetsyServer
+	server *Server
+}
+
+// NewGRPCServer wraps an existing Server so both APIs see the same data
+func NewGRPCServer(server *Server) *GRPCServer {
+	return &GRPCServer{server: server}
+}
+
+// Ensure that GRPCServer implements the FleetsyServer at compile time.
+var _ fleetsypb.FleetsyServer = (*GRPCServer)(nil)
+
+// (rpc PostHeartbeat)
+func (g *GRPCServer) PostHeartbeat(ctx context.Context, req *fleetsypb.PostHeartbeatRequest) (*emptypb.Empty, error) {
+	if err := g.addHeartbeat(ctx, req); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// (rpc PostStats)
+func (g *GRPCServer) PostStats(ctx context.Context, req *fleetsypb.PostStatsRequest) (*emptypb.Empty, error) {
+	if err := req.GetSentAt().CheckValid(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "sent_at is required")
+	}
+
+	newDeviceStats := DeviceStats{
+		SentAt:        req.GetSentAt().AsTime(),
+		UploadTime:    req.GetUploadTime(),
+		BytesUploaded: req.GetBytesUploaded(),
+		ClipDuration:  req.GetClipDuration(),
+		RetryCount:    int(req.GetRetryCount()),
+		NetworkType:   req.GetNetworkType(),
+	}
+	if req.ResultCode != nil {
+		resultCode := int(req.GetResultCode())
+		newDeviceStats.ResultCode = &resultCode
+	}
+	newDeviceStats.Extra = grpcStatsExtra(req)
+	if err := g.server.validateStats(req.GetDeviceId(), newDeviceStats); errors.Is(err, ErrDeviceNotFound) {
+		return nil, grpcError(err)
+	} else if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := g.server.addStats(ctx, req.GetDeviceId(), newDeviceStats); err != nil {
+		return nil, grpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// (rpc GetStats)
+func (g *GRPCServer) GetStats(ctx context.Context, req *fleetsypb.GetStatsRequest) (*fleetsypb.GetStatsResponse, error) {
+	stats, err := g.server.deviceStats(ctx, req.GetDeviceId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &fleetsypb.GetStatsResponse{
+		Uptime:        stats.Uptime,
+		AvgUploadTime: stats.AvgUploadTime,
+		ClockOffset:   stats.ClockOffset,
+		ClockDriftPpm: stats.ClockDriftPPM,
+		SkewedRecords: int64(stats.SkewedRecords),
+		Throughput:    stats.Throughput,
+		FailureRate:   stats.FailureRate,
+		Details:       stats.Details,
+
+		UploadTimeDistribution: grpcUploadTimeDistribution(stats.UploadTimeDistribution),
+	}, nil
+}
+
+// (rpc StreamHeartbeats)
+func (g *GRPCServer) StreamHeartbeats(stream fleetsypb.Fleetsy_StreamHeartbeatsServer) error {
+	response := &fleetsypb.StreamHeartbeatsResponse{}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(response)
+		}
+		if err != nil {
+			return err
+		}
+
+		// a gateway may forward heartbeats for many devices, so a bad one shouldn't end the stream
+		if err := g.addHeartbeat(stream.Context(), req); err != nil {
+			response.Rejected++
+			continue
+		}
+		response.Accepted++
+	}
+}
+
+// addHeartbeat validates and stores a single heartbeat request
+func (g *GRPCServer) addHeartbeat(ctx context.Context, req *fleetsypb.PostHeartbeatRequest) error {
+	if err := req.GetSentAt().CheckValid(); err != nil {
+		return status.Error(codes.InvalidArgument, "sent_at is required")
+	}
+	if err := g.server.addHeartbeat(ctx, req.GetDeviceId(), req.GetSentAt().AsTime()); err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+// grpcStatsExtra converts the type specific details of a stats request into the Extra map
+// the device types decode from a JSON body, so they validate and report them the same way
+func grpcStatsExtra(req *fleetsypb.PostStatsRequest) map[string]any {
+	switch details := req.GetDetails().(type) {
+	case *fleetsypb.PostStatsRequest_Doorbell:
+		extra := map[string]any{"event": details.Doorbell.GetEvent()}
+		if details.Doorbell.BatteryLevel != nil {
+			extra["battery_level"] = details.Doorbell.GetBatteryLevel()
+		}
+		return extra
+	case *fleetsypb.PostStatsRequest_Sensor:
+		extra := map[string]any{}
+		if details.Sensor.Temperature != nil {
+			extra["temperature"] = details.Sensor.GetTemperature()
+		}
+		if details.Sensor.Humidity != nil {
+			extra["humidity"] = details.Sensor.GetHumidity()
+		}
+		return extra
+	}
+	return nil
+}
+
+// grpcUploadTimeDistribution converts the REST distribution into its protobuf message
+func grpcUploadTimeDistribution(distribution *UploadTimeDistribution) *fleetsypb.UploadTimeDistribution {
+	if distribution == nil {
+		return nil
+	}
+
+	message := &fleetsypb.UploadTimeDistribution{
+		Count:  distribution.Count,
+		Min:    distribution.Min,
+		Max:    distribution.Max,
+		P50:    distribution.P50,
+		P90:    distribution.P90,
+		P95:    distribution.P95,
+		P99:    distribution.P99,
+		StdDev: distribution.StdDev,
+	}
+	for _, bucket := range distribution.Histogram {
+		message.Histogram = append(message.Histogram, &fleetsypb.HistogramBucket{Le: bucket.LE, Count: bucket.Count})
+	}
+	return message
+}
+
+// grpcError converts store errors into gRPC status errors
+func grpcError(err error) error {
+	if errors.Is(err, ErrDeviceNotFound) {
+		return status.Error(codes.NotFound, "Device not found")
+	}
+	return status.Error(codes.Internal, "Server Error")
+}
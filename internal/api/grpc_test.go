@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"fleetsy/pkg/fleetsypb"
+)
+
+// newGRPCClient serves a GRPCServer over an in memory connection and returns a client for it
+func newGRPCClient(t *testing.T, server *Server) fleetsypb.FleetsyClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	fleetsypb.RegisterFleetsyServer(grpcServer, NewGRPCServer(server))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return fleetsypb.NewFleetsyClient(conn)
+}
+
+func TestGRPCPostStatsDeviceTypes(t *testing.T) {
+	devices := map[string]Device{
+		"door1": {ID: "door1", Model: "doorbell"},
+		"sens1": {ID: "sens1", Model: "sensor"},
+	}
+	server := NewServer(devices,
+		map[string][]Heartbeat{"door1": {}, "sens1": {}},
+		map[string][]DeviceStats{"door1": {}, "sens1": {}},
+		Options{})
+	client := newGRPCClient(t, server)
+	sentAt := timestamppb.New(time.Now())
+
+	tests := []struct {
+		name string
+		req  *fleetsypb.PostStatsRequest
+		code codes.Code
+	}{
+		{name: "doorbell ring", code: codes.OK, req: &fleetsypb.PostStatsRequest{
+			DeviceId: "door1", SentAt: sentAt, UploadTime: int64(20 * time.Second),
+			Details: &fleetsypb.PostStatsRequest_Doorbell{Doorbell: &fleetsypb.DoorbellStats{Event: "ring", BatteryLevel: proto.Float64(80)}},
+		}},
+		{name: "doorbell motion", code: codes.OK, req: &fleetsypb.PostStatsRequest{
+			DeviceId: "door1", SentAt: sentAt, UploadTime: int64(10 * time.Second),
+			Details: &fleetsypb.PostStatsRequest_Doorbell{Doorbell: &fleetsypb.DoorbellStats{Event: "motion"}},
+		}},
+		{name: "doorbell without an event", code: codes.InvalidArgument, req: &fleetsypb.PostStatsRequest{
+			DeviceId: "door1", SentAt: sentAt, UploadTime: int64(10 * time.Second),
+		}},
+		{name: "doorbell battery out of range", code: codes.InvalidArgument, req: &fleetsypb.PostStatsRequest{
+			DeviceId: "door1", SentAt: sentAt, UploadTime: int64(10 * time.Second),
+			Details: &fleetsypb.PostStatsRequest_Doorbell{Doorbell: &fleetsypb.DoorbellStats{Event: "ring", BatteryLevel: proto.Float64(150)}},
+		}},
+		{name: "sensor reading", code: codes.OK, req: &fleetsypb.PostStatsRequest{
+			DeviceId: "sens1", SentAt: sentAt,
+			Details: &fleetsypb.PostStatsRequest_Sensor{Sensor: &fleetsypb.SensorStats{Temperature: proto.Float64(21.5), Humidity: proto.Float64(40)}},
+		}},
+		{name: "sensor without a temperature", code: codes.InvalidArgument, req: &fleetsypb.PostStatsRequest{
+			DeviceId: "sens1", SentAt: sentAt,
+			Details: &fleetsypb.PostStatsRequest_Sensor{Sensor: &fleetsypb.SensorStats{Humidity: proto.Float64(40)}},
+		}},
+		// the doorbell fields mean nothing to a sensor
+		{name: "sensor sent doorbell details", code: codes.InvalidArgument, req: &fleetsypb.PostStatsRequest{
+			DeviceId: "sens1", SentAt: sentAt,
+			Details: &fleetsypb.PostStatsRequest_Doorbell{Doorbell: &fleetsypb.DoorbellStats{Event: "ring"}},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.PostStats(context.Background(), test.req)
+			if code := status.Code(err); code != test.code {
+				t.Errorf("got %s (%v), want %s", code, err, test.code)
+			}
+		})
+	}
+
+	// the accepted stats are reported the same as ones posted over REST
+	doorbell, err := client.GetStats(context.Background(), &fleetsypb.GetStatsRequest{DeviceId: "door1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doorbell.Details["rings"] != 1 || doorbell.Details["motion_events"] != 1 || doorbell.Details["battery_level"] != 80 {
+		t.Errorf("got doorbell details %v", doorbell.Details)
+	}
+	sensor, err := client.GetStats(context.Background(), &fleetsypb.GetStatsRequest{DeviceId: "sens1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sensor.Details["avg_temperature"] != 21.5 || sensor.Details["avg_humidity"] != 40 {
+		t.Errorf("got sensor details %v", sensor.Details)
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// statuses of a readiness check
+const (
+	CheckOK      = "ok"
+	CheckFailing = "failing"
+	CheckSkipped = "skipped" // the check doesn't apply to this server and doesn't affect readiness
+)
+
+// the background workers started by main, each one has to be running for the server to be ready
+const (
+	WorkerPresence = "presence"
+	WorkerWebhooks = "webhooks"
+	WorkerAlerts   = "alerts"
+)
+
+// a worker that hasn't finished a pass in this many intervals is stuck, most likely waiting
+// on a lock, rather than just busy
+const workerStallIntervals = 3
+
+// how long the readiness check waits for the device store's write lock.  Heartbeats need the
+// same lock, so if it can't be had in this long they're piling up behind something.
+const readyLockTimeout = time.Second
+
+// the state of a background worker, updated by the worker itself
+type workerState struct {
+	running  bool
+	interval time.Duration
+	lastRun  time.Time // when the worker last finished a pass, or started if it hasn't yet
+}
+
+// response struct for the liveness GET requests
+type HealthGet struct {
+	Status    string    `json:"status"` // always ok, the process answering is the check
+	StartedAt time.Time `json:"started_at"`
+	Uptime    string    `json:"uptime"`
+}
+
+// struct for a single readiness check
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok, failing or skipped
+	Detail string `json:"detail"`
+}
+
+// struct for a background worker in the readiness response
+type WorkerStatus struct {
+	Name     string     `json:"name"`
+	Running  bool       `json:"running"`
+	Interval string     `json:"interval,omitempty"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+}
+
+// response struct for the readiness GET requests
+type ReadyGet struct {
+	Status  string         `json:"status"` // ready or not_ready
+	Checks  []HealthCheck  `json:"checks"`
+	Workers []WorkerStatus `json:"workers"`
+}
+
+// (GET /healthz)
+//
+// Healthz says the process is alive.  It doesn't look at anything the server depends on, so
+// an orchestrator only restarts the process when it has stopped answering altogether.
+func (s *Server) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, HealthGet{
+		Status:    "ok",
+		StartedAt: s.startedAt,
+		Uptime:    time.Since(s.startedAt).Round(time.Second).String(),
+	})
+}
+
+// (GET /readyz)
+//
+// Readyz says whether the server can accept heartbeats: the devices are loaded, the store can
+// be written to and the background workers are running.  It responds with 503 when any check
+// fails so an orchestrator stops routing traffic to it.
+func (s *Server) Readyz(w http.ResponseWriter, r *http.Request) {
+	workersCheck, workers := s.checkWorkers(time.Now().UTC())
+	response := ReadyGet{
+		Status: "ready",
+		Checks: []HealthCheck{
+			s.checkDevicesLoaded(r.Context()),
+			s.checkStorageWritable(r.Context()),
+			workersCheck,
+			// there's no write-ahead log to lag behind, the store only lives in memory
+			{Name: "wal_lag", Status: CheckSkipped, Detail: "the device store is held in memory and has no write-ahead log"},
+		},
+		Workers: workers,
+	}
+
+	code := http.StatusOK
+	for _, check := range response.Checks {
+		if check.Status == CheckFailing {
+			response.Status = "not_ready"
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkDevicesLoaded checks that devices.csv gave the server some devices to accept data for
+func (s *Server) checkDevicesLoaded(ctx context.Context) HealthCheck {
+	s.rlockDevices(ctx)
+	devices := len(s.devices)
+	s.deviceMutex.RUnlock()
+
+	if devices == 0 {
+		return HealthCheck{Name: "devices_loaded", Status: CheckFailing, Detail: "no devices were loaded from devices.csv"}
+	}
+	return HealthCheck{Name: "devices_loaded", Status: CheckOK, Detail: fmt.Sprintf("%d devices", devices)}
+}
+
+// checkStorageWritable checks the device store's write lock can be had within readyLockTimeout,
+// the same lock storing a heartbeat takes.  It queues for the lock like a heartbeat would, so
+// readers keep the lock from it only as long as they would keep it from a heartbeat.  While it
+// waits new readers queue behind it, the same as they do behind a heartbeat.
+//
+// The lock is taken in its own goroutine, which carries on waiting after the probe gives up and
+// releases the lock as soon as it gets it.  Only one probe waits at a time, a probe that finds
+// another still waiting fails straight away.
+func (s *Server) checkStorageWritable(ctx context.Context) HealthCheck {
+	if !s.readyLockWaiting.CompareAndSwap(false, true) {
+		return HealthCheck{Name: "storage_writable", Status: CheckFailing, Detail: "busy, an earlier probe is still waiting for the write lock"}
+	}
+
+	start := time.Now()
+	// buffered so the goroutine can finish after the probe has given up
+	acquired := make(chan time.Duration, 1)
+	go func() {
+		defer s.readyLockWaiting.Store(false)
+		s.deviceMutex.Lock()
+		waited := time.Since(start)
+		s.deviceMutex.Unlock()
+		acquired <- waited
+	}()
+
+	timeout := time.NewTimer(readyLockTimeout)
+	defer timeout.Stop()
+	select {
+	case waited := <-acquired:
+		return HealthCheck{Name: "storage_writable", Status: CheckOK, Detail: fmt.Sprintf("write lock taken after %s", waited)}
+	case <-timeout.C:
+		return HealthCheck{Name: "storage_writable", Status: CheckFailing, Detail: fmt.Sprintf("busy, write lock not taken after %s", readyLockTimeout)}
+	case <-ctx.Done():
+		return HealthCheck{Name: "storage_writable", Status: CheckFailing, Detail: "busy, the probe gave up waiting for the write lock"}
+	}
+}
+
+// checkWorkers checks that every background worker is running and has finished a pass
+// recently, and returns their states for the response
+func (s *Server) checkWorkers(now time.Time) (HealthCheck, []WorkerStatus) {
+	s.workerMutex.Lock()
+	defer s.workerMutex.Unlock()
+
+	workers := []WorkerStatus{}
+	var problems []string
+	for name, state := range s.workers {
+		worker := WorkerStatus{Name: name, Running: state.running}
+		if !state.running {
+			problems = append(problems, name+" is not running")
+			workers = append(workers, worker)
+			continue
+		}
+		lastRun := state.lastRun
+		worker.Interval = state.interval.String()
+		worker.LastRun = &lastRun
+		workers = append(workers, worker)
+
+		if since := now.Sub(lastRun); since > workerStallIntervals*state.interval {
+			problems = append(problems, fmt.Sprintf("%s last ran %s ago", name, since.Round(time.Second)))
+		}
+	}
+	slices.SortFunc(workers, func(a, b WorkerStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	// map order would shuffle the detail between requests
+	slices.Sort(problems)
+
+	if len(problems) > 0 {
+		return HealthCheck{Name: "background_workers", Status: CheckFailing, Detail: strings.Join(problems, ", ")}, workers
+	}
+	return HealthCheck{Name: "background_workers", Status: CheckOK, Detail: fmt.Sprintf("%d workers running", len(workers))}, workers
+}
+
+// workerStarted marks a background worker as running.  The returned function marks it
+// stopped, for the worker to defer.
+func (s *Server) workerStarted(name string, interval time.Duration) func() {
+	s.workerMutex.Lock()
+	s.workers[name] = &workerState{running: true, interval: interval, lastRun: time.Now().UTC()}
+	s.workerMutex.Unlock()
+
+	return func() {
+		s.workerMutex.Lock()
+		s.workers[name].running = false
+		s.workerMutex.Unlock()
+	}
+}
+
+// workerRan records that a background worker finished a pass
+func (s *Server) workerRan(name string) {
+	s.workerMutex.Lock()
+	s.workers[name].lastRun = time.Now().UTC()
+	s.workerMutex.Unlock()
+}
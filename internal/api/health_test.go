@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCheckStorageWritable(t *testing.T) {
+	server := NewServer(map[string]Device{}, map[string][]Heartbeat{}, map[string][]DeviceStats{}, Options{})
+
+	t.Run("under steady reader load", func(t *testing.T) {
+		// overlapping readers mean the lock is never free, but a queued writer still gets it
+		done := make(chan struct{})
+		var readers sync.WaitGroup
+		for range 8 {
+			readers.Go(func() {
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					server.deviceMutex.RLock()
+					time.Sleep(5 * time.Millisecond)
+					server.deviceMutex.RUnlock()
+				}
+			})
+		}
+		defer readers.Wait()
+		defer close(done)
+
+		if check := server.checkStorageWritable(context.Background()); check.Status != CheckOK {
+			t.Errorf("got %s (%s), want %s", check.Status, check.Detail, CheckOK)
+		}
+	})
+
+	t.Run("write lock held", func(t *testing.T) {
+		server.deviceMutex.Lock()
+		if check := server.checkStorageWritable(context.Background()); check.Status != CheckFailing {
+			t.Errorf("got %s (%s), want %s", check.Status, check.Detail, CheckFailing)
+		}
+		// the first probe is still queued, the next one doesn't queue behind it
+		if check := server.checkStorageWritable(context.Background()); check.Status != CheckFailing || check.Detail != "busy, an earlier probe is still waiting for the write lock" {
+			t.Errorf("got %s (%s), want the earlier probe to still be waiting", check.Status, check.Detail)
+		}
+		server.deviceMutex.Unlock()
+
+		// once the lock is released the waiting probe takes it and gives it back
+		deadline := time.Now().Add(time.Second)
+		for server.readyLockWaiting.Load() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if check := server.checkStorageWritable(context.Background()); check.Status != CheckOK {
+			t.Errorf("got %s (%s) after the lock was released, want %s", check.Status, check.Detail, CheckOK)
+		}
+	})
+}
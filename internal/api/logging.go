@@ -0,0 +1,193 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// error responses are kept up to this size so the logger can say why a request failed
+const maxLoggedErrorBody = 512
+
+// context key for the request log entry
+type requestLogKey struct{}
+
+// what the handlers can add to a request's log line
+type requestLog struct {
+	cause error
+	stack string // where the handler panicked, set by RecoverPanics
+}
+
+// recordError attaches the underlying cause of a failed request to its log line, for the
+// places where the response only says something generic like "Invalid request body"
+func recordError(r *http.Request, err error) {
+	if entry, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok && entry.cause == nil {
+		entry.cause = err
+	}
+}
+
+// RequestLogger logs a JSON line for every request with its request id, route, device id,
+// status, latency, trace id and, for failures, the cause.  It needs middleware.RequestID in
+// front of it, and TraceRequests too for the trace id.
+//
+// Heartbeats are most of the traffic, so only one in every heartbeatSample successful
+// heartbeats is logged.  Failed heartbeats are always logged.
+func RequestLogger(logger *slog.Logger, heartbeatSample int) func(http.Handler) http.Handler {
+	var heartbeats atomic.Uint64
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestId := middleware.GetReqID(r.Context())
+			w.Header().Set(middleware.RequestIDHeader, requestId)
+
+			entry := &requestLog{}
+			r = r.WithContext(context.WithValue(r.Context(), requestLogKey{}, entry))
+			ww := &errorCapturingWriter{WrapResponseWriter: middleware.NewWrapResponseWriter(w, r.ProtoMajor)}
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			routeContext := chi.RouteContext(r.Context())
+			route := ""
+			deviceId := ""
+			if routeContext != nil {
+				route = routeContext.RoutePattern()
+				deviceId = routeContext.URLParam("device_id")
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			case strings.HasSuffix(route, "/heartbeat") && heartbeatSample > 1:
+				if heartbeats.Add(1)%uint64(heartbeatSample) != 1 {
+					return
+				}
+			}
+
+			attrs := []slog.Attr{
+				slog.String("request_id", requestId),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", route),
+				slog.Int("status", status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int("bytes", ww.BytesWritten()),
+			}
+			if deviceId != "" {
+				attrs = append(attrs, slog.String("device_id", deviceId))
+			}
+			// lines up the log line with the request's trace
+			if id := traceId(r.Context()); id != "" {
+				attrs = append(attrs, slog.String("trace_id", id))
+			}
+			if cause := entry.cause; cause != nil {
+				attrs = append(attrs, slog.String("error", cause.Error()))
+			} else if status >= 400 {
+				attrs = append(attrs, slog.String("error", ww.errorMessage()))
+			}
+			if entry.stack != "" {
+				attrs = append(attrs, slog.String("stack", entry.stack))
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+// RecoverPanics turns a panicking handler into a 500 in place of middleware.Recoverer, which
+// writes the panic to stderr as plain text.  The panic and its stack go on the request's log
+// line instead, so it goes behind RequestLogger.  Without RequestLogger in front of it the
+// panic is logged on a line of its own.
+func RecoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// RequestLogger's writer already tracks whether the connection was hijacked
+		ww, ok := w.(*errorCapturingWriter)
+		if !ok {
+			ww = &errorCapturingWriter{WrapResponseWriter: middleware.NewWrapResponseWriter(w, r.ProtoMajor)}
+		}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// the server uses this to abort a response on purpose, it handles it itself
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			cause := fmt.Errorf("panic: %v", recovered)
+			stack := string(debug.Stack())
+			if entry, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+				entry.cause = cause
+				entry.stack = stack
+			} else {
+				slog.ErrorContext(r.Context(), "request panicked", "request_id", middleware.GetReqID(r.Context()), "error", cause.Error(), "stack", stack)
+			}
+			// a websocket has already taken over the connection, or the response was already started
+			if !ww.hijacked && ww.Status() == 0 {
+				ww.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// errorCapturingWriter keeps the start of error responses so their message can be logged
+type errorCapturingWriter struct {
+	middleware.WrapResponseWriter
+	body     []byte
+	hijacked bool // a handler took over the connection, nothing more can be written through the writer
+}
+
+// Write keeps up to maxLoggedErrorBody bytes of an error response
+func (w *errorCapturingWriter) Write(p []byte) (int, error) {
+	n, err := w.WrapResponseWriter.Write(p)
+	if w.Status() >= 400 && len(w.body) < maxLoggedErrorBody {
+		w.body = append(w.body, p[:min(n, maxLoggedErrorBody-len(w.body))]...)
+	}
+	return n, err
+}
+
+// errorMessage pulls the message out of an error response, which is either the api's JSON
+// error or plain text from http.Error
+func (w *errorCapturingWriter) errorMessage() string {
+	var response struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(w.body, &response) == nil && response.Message != "" {
+		return response.Message
+	}
+	if message := strings.TrimSpace(string(w.body)); message != "" {
+		return message
+	}
+	return http.StatusText(w.Status())
+}
+
+// Hijack hands the connection over for the websocket upgrade
+func (w *errorCapturingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(w.Unwrap()).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, buf, err
+}
+
+// Flush sends any buffered data to the client
+func (w *errorCapturingWriter) Flush() {
+	http.NewResponseController(w.Unwrap()).Flush()
+}
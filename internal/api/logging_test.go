@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// hijackableRecorder is a ResponseRecorder that can be hijacked and keeps every status written
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	statuses []int
+}
+
+func (w *hijackableRecorder) WriteHeader(status int) {
+	w.statuses = append(w.statuses, status)
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func (w *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, client := net.Pipe()
+	client.Close()
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+func TestRecoverPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		upgrade bool
+		handler http.HandlerFunc
+		want    []int
+	}{
+		{name: "panic", handler: func(http.ResponseWriter, *http.Request) { panic("boom") }, want: []int{http.StatusInternalServerError}},
+		// the websocket has the connection, there's nothing to write the 500 to
+		{name: "after hijacking", upgrade: true, want: nil, handler: func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			panic("boom")
+		}},
+		// the upgrade was asked for but the handler panicked before it happened
+		{name: "before hijacking", upgrade: true, handler: func(http.ResponseWriter, *http.Request) { panic("boom") }, want: []int{http.StatusInternalServerError}},
+		{name: "response already started", want: []int{http.StatusAccepted}, handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}},
+	}
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	logged := RequestLogger(discard, 1)
+	// without the request logger the panic goes to the default logger
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(discard)
+	for _, test := range tests {
+		for _, withLogger := range []bool{true, false} {
+			name := test.name
+			handler := RecoverPanics(test.handler)
+			if withLogger {
+				name += " with the request logger"
+				handler = logged(handler)
+			}
+			t.Run(name, func(t *testing.T) {
+				r := httptest.NewRequest("GET", "/api/v1/devices/cam1/ws", nil)
+				if test.upgrade {
+					r.Header.Set("Connection", "keep-alive, Upgrade")
+					r.Header.Set("Upgrade", "websocket")
+				}
+				w := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+				handler.ServeHTTP(w, r)
+				if !slices.Equal(w.statuses, test.want) {
+					t.Errorf("got statuses %v, want %v", w.statuses, test.want)
+				}
+			})
+		}
+	}
+}
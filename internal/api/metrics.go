@@ -0,0 +1,349 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"fleetsy/pkg/api"
+)
+
+// kinds of metric a device can report
+const (
+	MetricKindGauge   = "gauge"   // a value that goes up and down, eg cpu temperature
+	MetricKindCounter = "counter" // a value that only increases until the device resets it, eg bytes sent
+)
+
+// ErrMetricNotFound is returned when a metric isn't registered for the device's model
+var ErrMetricNotFound = errors.New("metric not found")
+
+// struct for the metric schema registry
+type MetricSchema struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"`
+	Unit        string   `json:"unit"`
+	Description string   `json:"description,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+}
+
+// struct for the device metrics arrays
+type MetricSample struct {
+	SentAt     time.Time `json:"sent_at"`
+	Value      float64   `json:"value"`
+	ReceivedAt time.Time `json:"-"`
+}
+
+// struct for the incoming metrics POST requests
+type MetricsPost struct {
+	Samples []MetricSamplePost `json:"samples"`
+}
+
+// struct for a single sample in a metrics POST request
+type MetricSamplePost struct {
+	Name   string   `json:"name"`
+	Value  *float64 `json:"value"` // pointer so a missing value isn't mistaken for zero
+	Unit   string   `json:"unit"`  // optional, checked against the schema when present
+	SentAt string   `json:"sent_at"`
+}
+
+// struct for the incoming metric schema PUT requests
+type MetricSchemaPut struct {
+	Kind        string   `json:"kind"`
+	Unit        string   `json:"unit"`
+	Description string   `json:"description"`
+	Min         *float64 `json:"min"`
+	Max         *float64 `json:"max"`
+}
+
+// summary of the samples of a single metric
+type MetricSummary struct {
+	Name     string     `json:"name"`
+	Kind     string     `json:"kind"`
+	Unit     string     `json:"unit"`
+	Count    int        `json:"count"`
+	Latest   *float64   `json:"latest,omitempty"`
+	LatestAt *time.Time `json:"latest_at,omitempty"`
+	Min      *float64   `json:"min,omitempty"`
+	Max      *float64   `json:"max,omitempty"`
+	Avg      *float64   `json:"avg,omitempty"`
+	Increase *float64   `json:"increase,omitempty"` // counters only
+}
+
+// response struct for the device metrics GET requests
+type MetricsGet struct {
+	Metrics []MetricSummary `json:"metrics"`
+}
+
+// response struct for the single metric GET requests
+type MetricGet struct {
+	Summary MetricSummary  `json:"summary"`
+	Samples []MetricSample `json:"samples"`
+}
+
+// response struct for the model metrics GET requests
+type MetricSchemasGet struct {
+	Metrics []MetricSchema `json:"metrics"`
+}
+
+// (POST /devices/{device_id}/metrics)
+func (s *Server) PostDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request, deviceId string) {
+	var newData MetricsPost
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	device, found := s.device(deviceId)
+	if !found {
+		writeNotFound(w)
+		return
+	}
+
+	// validate every sample before storing any so a bad batch doesn't get half applied
+	samples, err := s.validateMetricSamples(device.Model, newData.Samples)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.addMetricSamples(r.Context(), deviceId, samples); err != nil {
+		writeNotFound(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (GET /devices/{device_id}/metrics)
+func (s *Server) GetDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request, deviceId string) {
+	device, found := s.device(deviceId)
+	if !found {
+		writeNotFound(w)
+		return
+	}
+
+	s.rlockDevices(r.Context())
+	defer s.deviceMutex.RUnlock()
+
+	response := MetricsGet{Metrics: []MetricSummary{}}
+	for name, samples := range s.deviceMetricsMap[deviceId] {
+		schema, _ := s.metricSchema(device.Model, name)
+		response.Metrics = append(response.Metrics, summarizeMetric(schema, samples))
+	}
+	slices.SortFunc(response.Metrics, func(a, b MetricSummary) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	writeJSON(w, response)
+}
+
+// (GET /devices/{device_id}/metrics/{metric})
+func (s *Server) GetDevicesDeviceIdMetricsMetric(w http.ResponseWriter, r *http.Request, deviceId string, metric api.MetricPathParam, params api.GetDevicesDeviceIdMetricsMetricParams) {
+	device, found := s.device(deviceId)
+	if !found {
+		writeNotFound(w)
+		return
+	}
+
+	schema, registered := s.metricSchema(device.Model, metric)
+
+	s.rlockDevices(r.Context())
+	defer s.deviceMutex.RUnlock()
+
+	samples, reported := s.deviceMetricsMap[deviceId][metric]
+	if !registered && !reported {
+		writeError(w, http.StatusNotFound, "Metric not found")
+		return
+	}
+	if !registered {
+		// the schema was never registered for this model, fall back to what we know
+		schema = MetricSchema{Name: metric}
+	}
+
+	// only include the samples in the requested window
+	filtered := []MetricSample{}
+	for _, sample := range samples {
+		if params.From != nil && sample.SentAt.Before(*params.From) {
+			continue
+		}
+		if params.To != nil && !sample.SentAt.Before(*params.To) {
+			continue
+		}
+		filtered = append(filtered, sample)
+	}
+
+	writeJSON(w, MetricGet{
+		Summary: summarizeMetric(schema, filtered),
+		Samples: filtered,
+	})
+}
+
+// (GET /models/{model}/metrics)
+func (s *Server) GetModelsModelMetrics(w http.ResponseWriter, r *http.Request, model api.ModelPathParam) {
+	s.schemaMutex.RLock()
+	defer s.schemaMutex.RUnlock()
+
+	response := MetricSchemasGet{Metrics: []MetricSchema{}}
+	for _, schema := range s.metricSchemas[model] {
+		response.Metrics = append(response.Metrics, schema)
+	}
+	slices.SortFunc(response.Metrics, func(a, b MetricSchema) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	writeJSON(w, response)
+}
+
+// (PUT /models/{model}/metrics/{metric})
+func (s *Server) PutModelsModelMetricsMetric(w http.ResponseWriter, r *http.Request, model api.ModelPathParam, metric api.MetricPathParam) {
+	var newData MetricSchemaPut
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schema := MetricSchema{
+		Name:        metric,
+		Kind:        newData.Kind,
+		Unit:        newData.Unit,
+		Description: newData.Description,
+		Min:         newData.Min,
+		Max:         newData.Max,
+	}
+	if err := s.registerMetricSchema(model, schema); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// registerMetricSchema adds or replaces a metric in a model's registry
+func (s *Server) registerMetricSchema(model string, schema MetricSchema) error {
+	if schema.Name == "" {
+		return errors.New("metric name is required")
+	}
+	if schema.Kind != MetricKindGauge && schema.Kind != MetricKindCounter {
+		return fmt.Errorf("kind must be %s or %s", MetricKindGauge, MetricKindCounter)
+	}
+	if schema.Min != nil && schema.Max != nil && *schema.Min > *schema.Max {
+		return errors.New("min must not be larger than max")
+	}
+
+	s.schemaMutex.Lock()
+	defer s.schemaMutex.Unlock()
+
+	if s.metricSchemas[model] == nil {
+		s.metricSchemas[model] = make(map[string]MetricSchema)
+	}
+	s.metricSchemas[model][schema.Name] = schema
+	return nil
+}
+
+// metricSchema looks up a metric in a model's registry
+func (s *Server) metricSchema(model, name string) (MetricSchema, bool) {
+	s.schemaMutex.RLock()
+	defer s.schemaMutex.RUnlock()
+
+	schema, found := s.metricSchemas[model][name]
+	return schema, found
+}
+
+// pending sample that has passed validation and is ready to store
+type validMetricSample struct {
+	name   string
+	sample MetricSample
+}
+
+// validateMetricSamples checks a batch of samples against the model's registry
+func (s *Server) validateMetricSamples(model string, samples []MetricSamplePost) ([]validMetricSample, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("at least one sample is required")
+	}
+
+	valid := make([]validMetricSample, 0, len(samples))
+	for i, sample := range samples {
+		schema, found := s.metricSchema(model, sample.Name)
+		if !found {
+			return nil, fmt.Errorf("sample %d: metric %q is not registered for model %q", i, sample.Name, model)
+		}
+		if sample.Value == nil {
+			return nil, fmt.Errorf("sample %d: value is required", i)
+		}
+		value := *sample.Value
+		if sample.Unit != "" && sample.Unit != schema.Unit {
+			return nil, fmt.Errorf("sample %d: unit %q does not match the registered unit %q", i, sample.Unit, schema.Unit)
+		}
+		if schema.Kind == MetricKindCounter && value < 0 {
+			return nil, fmt.Errorf("sample %d: counter %q can't be negative", i, sample.Name)
+		}
+		if schema.Min != nil && value < *schema.Min {
+			return nil, fmt.Errorf("sample %d: %v is below the minimum of %v", i, value, *schema.Min)
+		}
+		if schema.Max != nil && value > *schema.Max {
+			return nil, fmt.Errorf("sample %d: %v is above the maximum of %v", i, value, *schema.Max)
+		}
+
+		sentAt, err := time.Parse(time.RFC3339, sample.SentAt)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: invalid sent_at", i)
+		}
+
+		valid = append(valid, validMetricSample{
+			name:   sample.Name,
+			sample: MetricSample{SentAt: sentAt, Value: value},
+		})
+	}
+	return valid, nil
+}
+
+// summarizeMetric works out the summary statistics for a metric's samples
+func summarizeMetric(schema MetricSchema, samples []MetricSample) MetricSummary {
+	summary := MetricSummary{
+		Name:  schema.Name,
+		Kind:  schema.Kind,
+		Unit:  schema.Unit,
+		Count: len(samples),
+	}
+	if len(samples) == 0 {
+		return summary
+	}
+
+	latest := samples[len(samples)-1]
+	minValue, maxValue, total := latest.Value, latest.Value, 0.0
+	increase := 0.0
+	for i, sample := range samples {
+		minValue = min(minValue, sample.Value)
+		maxValue = max(maxValue, sample.Value)
+		total += sample.Value
+
+		if i > 0 {
+			delta := sample.Value - samples[i-1].Value
+			if delta < 0 {
+				// the counter was reset, everything since the reset is new
+				delta = sample.Value
+			}
+			increase += delta
+		}
+	}
+	avg := total / float64(len(samples))
+
+	summary.Latest = &latest.Value
+	summary.LatestAt = &latest.SentAt
+	summary.Min = &minValue
+	summary.Max = &maxValue
+	summary.Avg = &avg
+	if schema.Kind == MetricKindCounter {
+		summary.Increase = &increase
+	}
+	return summary
+}
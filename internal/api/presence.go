@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"fleetsy/pkg/api"
+)
+
+// presence states of a device
+const (
+	PresenceUnknown   = "unknown"   // no heartbeat since the server started
+	PresenceOnline    = "online"    // heartbeats are arriving on time
+	PresenceLate      = "late"      // the next heartbeat is overdue
+	PresenceOffline   = "offline"   // several heartbeats in a row were missed
+	PresenceRecovered = "recovered" // a heartbeat arrived after the device was offline
+)
+
+// only the most recent presence changes are kept for each device
+const maxPresenceEvents = 1000
+
+// struct for a device's current presence
+type Presence struct {
+	State         string     `json:"state"`
+	Since         time.Time  `json:"since"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"` // server receive time
+}
+
+// struct for the device presence event arrays
+type PresenceEvent struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// response struct for the device GET requests
+type DeviceGet struct {
+	Device
+	Presence Presence `json:"presence"`
+}
+
+// response struct for the presence events GET requests
+type PresenceEventsGet struct {
+	Events []PresenceEvent `json:"events"`
+}
+
+// presence tracking for a single device
+type devicePresence struct {
+	Presence
+	interval time.Duration // copied from the device type so checks don't need the device lock
+	events   []PresenceEvent
+}
+
+// (GET /devices/{device_id})
+func (s *Server) GetDevicesDeviceId(w http.ResponseWriter, r *http.Request, deviceId string) {
+	device, found := s.device(deviceId)
+	if !found {
+		writeNotFound(w)
+		return
+	}
+
+	s.presenceMutex.Lock()
+	presence := s.presence[deviceId].Presence
+	s.presenceMutex.Unlock()
+
+	writeJSON(w, DeviceGet{Device: device, Presence: presence})
+}
+
+// (GET /devices/{device_id}/presence/events)
+func (s *Server) GetDevicesDeviceIdPresenceEvents(w http.ResponseWriter, r *http.Request, deviceId string, params api.GetDevicesDeviceIdPresenceEventsParams) {
+	s.presenceMutex.Lock()
+	defer s.presenceMutex.Unlock()
+
+	presence, found := s.presence[deviceId]
+	if !found {
+		writeNotFound(w)
+		return
+	}
+
+	response := PresenceEventsGet{Events: []PresenceEvent{}}
+	for _, event := range presence.events {
+		if params.From != nil && event.At.Before(*params.From) {
+			continue
+		}
+		if params.To != nil && !event.At.Before(*params.To) {
+			continue
+		}
+		response.Events = append(response.Events, event)
+	}
+
+	writeJSON(w, response)
+}
+
+// RunPresence checks every device for missed heartbeats until ctx is cancelled.
+// Heartbeats move devices back online as they arrive, this is what notices them going quiet.
+func (s *Server) RunPresence(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	defer s.workerStarted(WorkerPresence, every)()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.checkPresence(now.UTC())
+			s.workerRan(WorkerPresence)
+		}
+	}
+}
+
+// checkPresence moves devices that have missed heartbeats to late or offline
+func (s *Server) checkPresence(now time.Time) {
+	s.presenceMutex.Lock()
+	defer s.presenceMutex.Unlock()
+
+	for deviceId, presence := range s.presence {
+		// nothing to be late for until the first heartbeat
+		if presence.LastHeartbeat == nil {
+			continue
+		}
+
+		elapsed := now.Sub(*presence.LastHeartbeat)
+		switch {
+		case elapsed > time.Duration(s.options.OfflineAfterMissed)*presence.interval:
+			s.changePresence(deviceId, presence, PresenceOffline, now)
+		case elapsed > presence.interval+s.options.PresenceGrace && presence.State != PresenceOffline:
+			s.changePresence(deviceId, presence, PresenceLate, now)
+		}
+	}
+}
+
+// presenceHeartbeat updates a device's presence when a heartbeat arrives
+func (s *Server) presenceHeartbeat(deviceId string, receivedAt time.Time) {
+	s.presenceMutex.Lock()
+	defer s.presenceMutex.Unlock()
+
+	presence, found := s.presence[deviceId]
+	if !found {
+		return
+	}
+	presence.LastHeartbeat = &receivedAt
+
+	switch presence.State {
+	case PresenceOffline:
+		s.changePresence(deviceId, presence, PresenceRecovered, receivedAt)
+	case PresenceUnknown, PresenceLate, PresenceRecovered:
+		// a recovered device is back online once it sends a second heartbeat on time
+		s.changePresence(deviceId, presence, PresenceOnline, receivedAt)
+	}
+}
+
+// changePresence moves the device to a new state and records the event, if the state changed.
+// The caller must hold the presence lock.
+func (s *Server) changePresence(deviceId string, p *devicePresence, state string, at time.Time) {
+	if p.State == state {
+		return
+	}
+
+	event := PresenceEvent{From: p.State, To: state, At: at}
+	p.events = append(p.events, event)
+	if len(p.events) > maxPresenceEvents {
+		p.events = p.events[len(p.events)-maxPresenceEvents:]
+	}
+	p.State = state
+	p.Since = at
+	s.notifyPresence(deviceId, event)
+}
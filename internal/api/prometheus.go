@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// the server's own Prometheus metrics, the device telemetry in metrics.go is something else
+type serverMetrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	ingested        *prometheus.CounterVec
+	lockWait        *prometheus.HistogramVec
+}
+
+// descriptions of the metrics worked out when they're scraped
+var (
+	devicesDesc             = prometheus.NewDesc("fleetsy_devices", "Devices loaded from devices.csv.", nil, nil)
+	storeRecordsDesc        = prometheus.NewDesc("fleetsy_store_records", "Records held in the device store.", []string{"kind"}, nil)
+	alertsDesc              = prometheus.NewDesc("fleetsy_alerts", "Alerts held in the alert store.", []string{"state"}, nil)
+	notificationsDesc       = prometheus.NewDesc("fleetsy_notifications", "Notifications held in the delivery queue.", []string{"status"}, nil)
+	heartbeatAgeDesc        = prometheus.NewDesc("fleetsy_device_last_heartbeat_age_seconds", "Time since the server received the device's last heartbeat.", []string{"device_id", "group"}, nil)
+	deviceUptimeDesc        = prometheus.NewDesc("fleetsy_device_uptime_percent", "The device's uptime, worked out the same way as the stats endpoint.", []string{"device_id", "group"}, nil)
+	avgUploadTimeDesc       = prometheus.NewDesc("fleetsy_device_avg_upload_time_seconds", "The device's average upload time.", []string{"device_id", "group"}, nil)
+	deviceGaugesDroppedDesc = prometheus.NewDesc("fleetsy_device_gauges_dropped", "Devices left out of the per device gauges by -metrics-max-devices.", nil, nil)
+)
+
+// newServerMetrics creates the metrics and registers them along with the scrape time collectors
+func newServerMetrics(s *Server) *serverMetrics {
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "fleetsy_http_requests_total",
+			Help: "HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "fleetsy_http_request_duration_seconds",
+			Help:    "HTTP request latency by route pattern and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		ingested: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "fleetsy_ingested_records_total",
+			Help: "Records added to the device store from every ingestion path, by kind.",
+		}, []string{"kind"}),
+		lockWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "fleetsy_device_lock_wait_seconds",
+			Help: "Time spent waiting for the device store lock, by mode.",
+			// lock waits are usually tiny, so start the buckets at 1µs
+			Buckets: prometheus.ExponentialBuckets(1e-6, 4, 10),
+		}, []string{"mode"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.ingested,
+		m.lockWait,
+		storeCollector{s},
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// MetricsHandler serves the server's metrics in the Prometheus text format
+func (s *Server) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{})
+}
+
+// InstrumentRequests counts and times every request by its route pattern rather than its path,
+// so device ids don't end up in the labels
+func (s *Server) InstrumentRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+			route = routeContext.RoutePattern()
+		}
+		status := ww.Status()
+		// handlers that only write a body never set the status explicitly
+		if status == 0 {
+			status = http.StatusOK
+		}
+		s.metrics.requests.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+		s.metrics.requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// lockDevices takes the device lock for writing, recording how long it waited
+func (s *Server) lockDevices(ctx context.Context) {
+	defer traceLock(ctx, "write")()
+	start := time.Now()
+	s.deviceMutex.Lock()
+	s.metrics.lockWait.WithLabelValues("write").Observe(time.Since(start).Seconds())
+}
+
+// rlockDevices takes the device lock for reading, recording how long it waited
+func (s *Server) rlockDevices(ctx context.Context) {
+	defer traceLock(ctx, "read")()
+	start := time.Now()
+	s.deviceMutex.RLock()
+	s.metrics.lockWait.WithLabelValues("read").Observe(time.Since(start).Seconds())
+}
+
+// storeCollector reports the size of the stores, and the per device gauges if they're enabled,
+// when the metrics are scraped
+type storeCollector struct {
+	s *Server
+}
+
+// Describe sends nothing, which makes this an unchecked collector since the
+// per device gauges depend on the options
+func (c storeCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect works out the store sizes, taking each lock in turn
+func (c storeCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.s
+	now := time.Now().UTC()
+
+	s.rlockDevices(context.Background())
+	heartbeats, stats, samples := 0, 0, 0
+	for deviceId := range s.devices {
+		heartbeats += len(s.deviceHeartbeatMap[deviceId])
+		stats += len(s.deviceStatsMap[deviceId])
+		for _, metric := range s.deviceMetricsMap[deviceId] {
+			samples += len(metric)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(devicesDesc, prometheus.GaugeValue, float64(len(s.devices)))
+	ch <- prometheus.MustNewConstMetric(storeRecordsDesc, prometheus.GaugeValue, float64(heartbeats), "heartbeat")
+	ch <- prometheus.MustNewConstMetric(storeRecordsDesc, prometheus.GaugeValue, float64(stats), "stats")
+	ch <- prometheus.MustNewConstMetric(storeRecordsDesc, prometheus.GaugeValue, float64(samples), "metric_sample")
+	if s.options.MetricsDeviceGauges {
+		c.collectDevices(ch, now)
+	}
+	s.deviceMutex.RUnlock()
+
+	s.alertMutex.Lock()
+	alerts := map[string]int{AlertPending: 0, AlertFiring: 0, AlertResolved: 0}
+	for _, alert := range s.alerts {
+		alerts[alert.State]++
+	}
+	s.alertMutex.Unlock()
+	for state, count := range alerts {
+		ch <- prometheus.MustNewConstMetric(alertsDesc, prometheus.GaugeValue, float64(count), state)
+	}
+
+	s.webhookMutex.Lock()
+	notifications := map[string]int{NotificationPending: 0, NotificationDelivered: 0, NotificationDead: 0}
+	for _, notification := range s.notifications {
+		notifications[notification.Status]++
+	}
+	s.webhookMutex.Unlock()
+	for status, count := range notifications {
+		ch <- prometheus.MustNewConstMetric(notificationsDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+// collectDevices sends the per device gauges.  Every device adds a series to each gauge, so
+// they can be limited to some groups and capped, taking devices in id order so the same ones
+// are reported every scrape.  The caller must hold the read lock.
+func (c storeCollector) collectDevices(ch chan<- prometheus.Metric, now time.Time) {
+	s := c.s
+	var deviceIds []string
+	for deviceId, device := range s.devices {
+		if len(s.options.MetricsDeviceGroups) > 0 && !slices.Contains(s.options.MetricsDeviceGroups, device.Group) {
+			continue
+		}
+		deviceIds = append(deviceIds, deviceId)
+	}
+	slices.Sort(deviceIds)
+
+	dropped := 0
+	if s.options.MetricsMaxDevices > 0 && len(deviceIds) > s.options.MetricsMaxDevices {
+		dropped = len(deviceIds) - s.options.MetricsMaxDevices
+		deviceIds = deviceIds[:s.options.MetricsMaxDevices]
+	}
+	ch <- prometheus.MustNewConstMetric(deviceGaugesDroppedDesc, prometheus.GaugeValue, float64(dropped))
+
+	for _, deviceId := range deviceIds {
+		device := s.devices[deviceId]
+		heartbeats := s.deviceHeartbeatMap[deviceId]
+		if len(heartbeats) > 0 {
+			age := now.Sub(heartbeats[len(heartbeats)-1].ReceivedAt)
+			ch <- prometheus.MustNewConstMetric(heartbeatAgeDesc, prometheus.GaugeValue, age.Seconds(), deviceId, device.Group)
+		}
+		uptime := s.deviceUptime(device, heartbeats, nil, nil)
+		ch <- prometheus.MustNewConstMetric(deviceUptimeDesc, prometheus.GaugeValue, float64(uptime), deviceId, device.Group)
+		if uploadTimes := s.uploadTimeSketches[deviceId]; uploadTimes.Count() > 0 {
+			avg := time.Duration(uploadTimes.Mean())
+			ch <- prometheus.MustNewConstMetric(avgUploadTimeDesc, prometheus.GaugeValue, avg.Seconds(), deviceId, device.Group)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"fleetsy/internal/sketch"
+)
+
+// how often RunRetention drops old records
+const retentionInterval = time.Minute
+
+// WorkerRetention is the background worker that drops records older than Options.Retention,
+// only started when there's a retention period
+const WorkerRetention = "retention"
+
+// RunRetention drops heartbeats, stats, metric samples and anomalies received longer ago than
+// the retention period until ctx is cancelled.  The upload time sketches are rebuilt from the
+// stats that are kept.  The anomaly baselines are moving averages that already favour recent
+// values, so they're left alone and stay lifetime aggregates.
+func (s *Server) RunRetention(ctx context.Context) {
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+	defer s.workerStarted(WorkerRetention, retentionInterval)()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if dropped := s.dropRecords(now.UTC().Add(-s.options.Retention)); dropped > 0 {
+				slog.Debug("dropped records past the retention period", "records", dropped)
+			}
+			s.workerRan(WorkerRetention)
+		}
+	}
+}
+
+// dropRecords drops every record received before cutoff, returning how many went
+func (s *Server) dropRecords(cutoff time.Time) int {
+	s.lockDevices(context.Background())
+	defer s.deviceMutex.Unlock()
+
+	dropped := 0
+	for deviceId, heartbeats := range s.deviceHeartbeatMap {
+		s.deviceHeartbeatMap[deviceId], dropped = dropBefore(heartbeats, cutoff, dropped, func(heartbeat Heartbeat) time.Time {
+			return heartbeat.ReceivedAt
+		})
+	}
+	for deviceId, stats := range s.deviceStatsMap {
+		before := dropped
+		s.deviceStatsMap[deviceId], dropped = dropBefore(stats, cutoff, dropped, func(stats DeviceStats) time.Time {
+			return stats.ReceivedAt
+		})
+		// a sketch can't forget values, so it's rebuilt from the stats that are left to keep
+		// the percentiles and sketch means in line with avg_upload_time
+		if dropped > before {
+			s.uploadTimeSketches[deviceId] = uploadTimeSketch(s.deviceStatsMap[deviceId])
+		}
+	}
+	for _, metrics := range s.deviceMetricsMap {
+		for name, samples := range metrics {
+			metrics[name], dropped = dropBefore(samples, cutoff, dropped, func(sample MetricSample) time.Time {
+				return sample.ReceivedAt
+			})
+		}
+	}
+	for deviceId, anomalies := range s.deviceAnomalies {
+		s.deviceAnomalies[deviceId], dropped = dropBefore(anomalies, cutoff, dropped, func(anomaly Anomaly) time.Time {
+			return anomaly.DetectedAt
+		})
+	}
+	return dropped
+}
+
+// dropBefore drops the records at the start of a history that were received before cutoff.
+// Histories are appended to as records arrive, so the old ones are all at the start.  What's
+// kept is copied so the dropped records can be garbage collected.
+func dropBefore[T any](records []T, cutoff time.Time, dropped int, receivedAt func(T) time.Time) ([]T, int) {
+	keep := slices.IndexFunc(records, func(record T) bool {
+		return !receivedAt(record).Before(cutoff)
+	})
+	if keep < 0 {
+		keep = len(records)
+	}
+	if keep == 0 {
+		return records, dropped
+	}
+	return append(make([]T, 0, len(records)-keep), records[keep:]...), dropped + keep
+}
+
+// uploadTimeSketch builds an upload time sketch from a device's stats
+func uploadTimeSketch(stats []DeviceStats) *sketch.Sketch {
+	uploadTimes := sketch.New(uploadTimeAccuracy)
+	for _, record := range stats {
+		if record.UploadTime > 0 {
+			uploadTimes.Add(float64(record.UploadTime))
+		}
+	}
+	return uploadTimes
+}
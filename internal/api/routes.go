@@ -0,0 +1,300 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"fleetsy/pkg/api"
+)
+
+// outputs an alert route can send to
+const (
+	RouteAlertmanager = "alertmanager"
+	RouteEmail        = "email"
+)
+
+// AlertEvents is the list of events an alert route can send
+var AlertEvents = []string{EventAlertFiring, EventAlertResolved, EventAlertEscalated}
+
+// struct for the alert route registry
+type Route struct {
+	ID           string              `json:"id"`
+	Type         string              `json:"type"`
+	Matchers     []Matcher           `json:"matchers"`
+	Events       []string            `json:"events"`
+	Alertmanager *AlertmanagerOutput `json:"alertmanager,omitempty"`
+	Email        *EmailOutput        `json:"email,omitempty"`
+
+	lastResend time.Time // when the firing alerts were last sent to the Alertmanager again
+}
+
+// struct for the incoming route PUT requests
+type RoutePut struct {
+	Type         string              `json:"type"`
+	Matchers     []Matcher           `json:"matchers"`
+	Events       []string            `json:"events"`
+	Alertmanager *AlertmanagerOutput `json:"alertmanager"`
+	Email        *EmailOutput        `json:"email"`
+}
+
+// response struct for the routes GET requests
+type RoutesGet struct {
+	Routes []Route `json:"routes"`
+}
+
+// (GET /routes)
+func (s *Server) GetRoutes(w http.ResponseWriter, r *http.Request) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	response := RoutesGet{Routes: []Route{}}
+	for _, route := range s.routes {
+		response.Routes = append(response.Routes, *route)
+	}
+	slices.SortFunc(response.Routes, func(a, b Route) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	writeJSON(w, response)
+}
+
+// (GET /routes/{route})
+func (s *Server) GetRoutesRoute(w http.ResponseWriter, r *http.Request, route api.RoutePathParam) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	found, ok := s.routes[route]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+	writeJSON(w, found)
+}
+
+// (PUT /routes/{route})
+func (s *Server) PutRoutesRoute(w http.ResponseWriter, r *http.Request, route api.RoutePathParam) {
+	var newData RoutePut
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	newRoute, err := newAlertRoute(route, newData)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.webhookMutex.Lock()
+	s.routes[route] = newRoute
+	s.webhookMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (DELETE /routes/{route})
+func (s *Server) DeleteRoutesRoute(w http.ResponseWriter, r *http.Request, route api.RoutePathParam) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	if _, found := s.routes[route]; !found {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+	delete(s.routes, route)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (POST /routes/{route}/test)
+func (s *Server) PostRoutesRouteTest(w http.ResponseWriter, r *http.Request, route api.RoutePathParam) {
+	s.webhookMutex.Lock()
+	found, ok := s.routes[route]
+	s.webhookMutex.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	// sent straight away rather than queued so the caller finds out if it worked
+	now := time.Now().UTC()
+	payload, err := found.payload(EventAlertFiring, []Alert{sampleAlert(now)})
+	if err == nil {
+		err = s.sendRoute(r.Context(), found, payload)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newAlertRoute validates a route PUT request
+func newAlertRoute(id string, newData RoutePut) (*Route, error) {
+	route := &Route{
+		ID:       id,
+		Type:     newData.Type,
+		Matchers: newData.Matchers,
+		Events:   newData.Events,
+	}
+	if route.Matchers == nil {
+		route.Matchers = []Matcher{}
+	}
+	if route.Events == nil {
+		route.Events = []string{}
+	}
+	if err := compileMatchers(route.Matchers); err != nil {
+		return nil, err
+	}
+	for _, event := range route.Events {
+		if !slices.Contains(AlertEvents, event) {
+			return nil, fmt.Errorf("events must be from %v", AlertEvents)
+		}
+	}
+
+	switch route.Type {
+	case RouteAlertmanager:
+		if newData.Alertmanager == nil {
+			return nil, errors.New("alertmanager routes need an alertmanager output")
+		}
+		if err := newData.Alertmanager.validate(); err != nil {
+			return nil, err
+		}
+		route.Alertmanager = newData.Alertmanager
+	case RouteEmail:
+		if newData.Email == nil {
+			return nil, errors.New("email routes need an email output")
+		}
+		if err := newData.Email.validate(); err != nil {
+			return nil, err
+		}
+		route.Email = newData.Email
+	default:
+		return nil, fmt.Errorf("type must be %s or %s", RouteAlertmanager, RouteEmail)
+	}
+	return route, nil
+}
+
+// wants reports whether the route sends an event for an alert
+func (route *Route) wants(event string, alert Alert) bool {
+	// the Alertmanager does its own escalation
+	if route.Type == RouteAlertmanager && event == EventAlertEscalated {
+		return false
+	}
+	if len(route.Events) > 0 && !slices.Contains(route.Events, event) {
+		return false
+	}
+	return matchLabels(route.Matchers, alert.Labels)
+}
+
+// payload builds what is sent for the alerts, once so every attempt sends the same thing apart
+// from the Alertmanager's endsAt
+func (route *Route) payload(event string, alerts []Alert) ([]byte, error) {
+	switch route.Type {
+	case RouteAlertmanager:
+		return alertmanagerPayload(alerts)
+	case RouteEmail:
+		return route.Email.payload(event, alerts[0])
+	}
+	return nil, fmt.Errorf("unknown route type %q", route.Type)
+}
+
+// sendRoute makes a single attempt at sending a payload through a route
+func (s *Server) sendRoute(ctx context.Context, route *Route, payload []byte) error {
+	switch route.Type {
+	case RouteAlertmanager:
+		return s.postAlertmanager(ctx, route.Alertmanager, payload)
+	case RouteEmail:
+		return s.sendEmail(ctx, route.Email, payload)
+	}
+	return fmt.Errorf("unknown route type %q", route.Type)
+}
+
+// notifyRoutes queues an alert change for every route that wants it
+func (s *Server) notifyRoutes(event string, alert Alert) {
+	now := time.Now().UTC()
+
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	for _, route := range s.routes {
+		if !route.wants(event, alert) {
+			continue
+		}
+		s.queueRoute(route, event, alert.DeviceID, []Alert{alert}, now)
+	}
+}
+
+// resendAlertmanager sends the firing alerts to every Alertmanager route again once the resend
+// interval has passed.  The Alertmanager resolves alerts it stops hearing about, so they have
+// to be repeated for as long as they fire, the same as Prometheus does.
+func (s *Server) resendAlertmanager(firing []Alert, now time.Time) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	for _, route := range s.routes {
+		if route.Type != RouteAlertmanager || now.Sub(route.lastResend) < alertmanagerResendInterval {
+			continue
+		}
+		route.lastResend = now
+
+		var alerts []Alert
+		for _, alert := range firing {
+			if route.wants(EventAlertFiring, alert) {
+				alerts = append(alerts, alert)
+			}
+		}
+		if len(alerts) > 0 {
+			s.queueRoute(route, EventAlertFiring, "", alerts, now)
+		}
+	}
+}
+
+// queueRoute adds a notification for a route to the delivery queue.
+// The caller must hold the webhook lock.
+func (s *Server) queueRoute(route *Route, event, deviceId string, alerts []Alert, now time.Time) {
+	payload, err := route.payload(event, alerts)
+	if err != nil {
+		slog.Error("failed to build route notification", "event", event, "route", route.ID, "error", err)
+		return
+	}
+	s.queueNotification(&Notification{
+		Route:    route.ID,
+		Event:    event,
+		DeviceID: deviceId,
+		Payload:  payload,
+	}, now)
+}
+
+// sampleAlert is the made up alert sent by route tests
+func sampleAlert(now time.Time) Alert {
+	value := 0.0
+	return Alert{
+		ID:       "test",
+		Rule:     "test",
+		DeviceID: "test",
+		State:    AlertFiring,
+		Labels: map[string]string{
+			"rule":      "test",
+			"severity":  SeverityWarning,
+			"device_id": "test",
+			"model":     DefaultDeviceModel,
+		},
+		Value:       &value,
+		ActiveSince: now,
+		FiredAt:     &now,
+	}
+}
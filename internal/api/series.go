@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"fleetsy/pkg/api"
+)
+
+// series that can be requested from the series endpoint
+const (
+	SeriesUptime     = "uptime"
+	SeriesUploadTime = "upload_time"
+)
+
+// a series can't have more buckets than this, so a tiny step over a long range can't eat all the memory
+const maxSeriesPoints = 10000
+
+// response struct for the series GET requests
+type SeriesGet struct {
+	Metric string        `json:"metric"`
+	Unit   string        `json:"unit"` // percent for uptime, ns for upload_time
+	Step   string        `json:"step"`
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Points []SeriesPoint `json:"points"`
+}
+
+// a single bucket of a series
+type SeriesPoint struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"` // heartbeats or uploads in the bucket
+	// nil when there's nothing to chart so charts show a gap: an upload_time bucket without
+	// uploads, or an uptime bucket where no heartbeat was due yet.  An uptime bucket where
+	// heartbeats were due but none arrived is 0.
+	Value *float64 `json:"value"`
+}
+
+// (GET /devices/{device_id}/series)
+func (s *Server) GetDevicesDeviceIdSeries(w http.ResponseWriter, r *http.Request, deviceId string, params api.GetDevicesDeviceIdSeriesParams) {
+	if params.Metric != SeriesUptime && params.Metric != SeriesUploadTime {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("metric must be %s or %s", SeriesUptime, SeriesUploadTime))
+		return
+	}
+	step, err := time.ParseDuration(params.Step)
+	if err != nil || step <= 0 {
+		writeError(w, http.StatusBadRequest, "step must be a positive duration. Eg: 1h")
+		return
+	}
+
+	response, err := s.deviceSeries(r.Context(), deviceId, params.Metric, step, params.From, params.To)
+	if errors.Is(err, ErrDeviceNotFound) {
+		writeNotFound(w)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// deviceSeries buckets one of the device's series by step.
+// from and to default to the range of the device's data.
+func (s *Server) deviceSeries(ctx context.Context, deviceId, metric string, step time.Duration, from, to *time.Time) (SeriesGet, error) {
+	ctx, span := startStoreSpan(ctx, "device_series", attribute.String("fleetsy.device_id", deviceId), attribute.String("fleetsy.series", metric))
+	defer span.End()
+
+	// lock the mutex for reading
+	s.rlockDevices(ctx)
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.RUnlock()
+
+	device, found := s.devices[deviceId]
+	if !found {
+		return SeriesGet{}, ErrDeviceNotFound
+	}
+
+	// flatten the series into timestamps and values so both metrics bucket the same way
+	var times []time.Time
+	var values []float64
+	heartbeats := s.deviceHeartbeatMap[deviceId]
+	unit := "ns"
+	switch metric {
+	case SeriesUptime:
+		unit = "percent"
+		for _, heartbeat := range heartbeats {
+			times = append(times, heartbeat.SentAt)
+		}
+	case SeriesUploadTime:
+		for _, stats := range s.deviceStatsMap[deviceId] {
+			if stats.UploadTime <= 0 {
+				continue
+			}
+			times = append(times, stats.SentAt)
+			values = append(values, float64(stats.UploadTime))
+		}
+	}
+
+	// work out the range, buckets line up with multiples of the step
+	var start, end time.Time
+	if from != nil {
+		start = *from
+	} else if len(times) > 0 {
+		start = times[0]
+	}
+	if to != nil {
+		end = *to
+	} else if len(times) > 0 {
+		// assuming the data arrived in chronological order, make sure the last record gets a bucket
+		end = times[len(times)-1].Add(step)
+	}
+	start = start.UTC().Truncate(step)
+	end = end.UTC()
+	if end.Before(start) {
+		return SeriesGet{}, errors.New("to must be after from")
+	}
+	// Sub saturates for ranges over ~292 years, which would leave the buckets short of to
+	length := end.Sub(start)
+	if !start.Add(length).Equal(end) {
+		return SeriesGet{}, errors.New("the range between from and to is too long")
+	}
+	// round up without adding step-1 first, that can overflow when the range is near the limit
+	buckets := int(length / step)
+	if length%step != 0 {
+		buckets++
+	}
+	if buckets > maxSeriesPoints {
+		return SeriesGet{}, fmt.Errorf("the range would need %d buckets, the most is %d", buckets, maxSeriesPoints)
+	}
+
+	// every bucket is included, even the ones without data
+	points := make([]SeriesPoint, buckets)
+	totals := make([]float64, buckets)
+	for i := range points {
+		points[i].Start = start.Add(time.Duration(i) * step)
+	}
+	for i, t := range times {
+		if t.Before(start) || !t.Before(end) {
+			continue
+		}
+		bucket := int(t.Sub(start) / step)
+		points[bucket].Count++
+		if values != nil {
+			totals[bucket] += values[i]
+		}
+	}
+
+	if metric == SeriesUptime {
+		s.uptimeBuckets(device, heartbeats, points, step, end)
+	} else {
+		for i := range points {
+			if points[i].Count == 0 {
+				continue
+			}
+			value := totals[i] / float64(points[i].Count)
+			points[i].Value = &value
+		}
+	}
+
+	return SeriesGet{
+		Metric: metric,
+		Unit:   unit,
+		Step:   step.String(),
+		From:   start,
+		To:     end,
+		Points: points,
+	}, nil
+}
+
+// uptimeBuckets fills in the uptime of each bucket the same way as the slot based uptime modes,
+// over the part of the bucket from when the device could be heard from until now.  Span mode
+// has no meaning for a single bucket, so it's counted from when the device could be heard from
+// like the registration mode.  The caller must hold the read lock.
+func (s *Server) uptimeBuckets(device Device, heartbeats []Heartbeat, points []SeriesPoint, step time.Duration, end time.Time) {
+	interval := deviceTypeFor(device.Model).HeartbeatInterval()
+	start, found := s.slotsStart(device, heartbeats)
+	if !found {
+		return
+	}
+	now := time.Now().UTC()
+
+	for i := range points {
+		from := points[i].Start
+		if start.After(from) {
+			from = start
+		}
+		to := points[i].Start.Add(step)
+		if end.Before(to) {
+			to = end
+		}
+		if now.Before(to) {
+			to = now
+		}
+
+		// heartbeats up to half an interval early still land in the bucket's first slot
+		observed, expected := countSlots(heartbeatsBetween(heartbeats, from.Add(-interval/2), to), from, to, interval, s.options.UptimeTolerance)
+		// nothing was due in the bucket
+		if expected == 0 {
+			continue
+		}
+		value := float64(observed) / float64(expected) * 100
+		points[i].Value = &value
+	}
+}
+
+// heartbeatsBetween returns the heartbeats sent from from until to, assuming they were
+// received in chronological order
+func heartbeatsBetween(heartbeats []Heartbeat, from, to time.Time) []Heartbeat {
+	first := sort.Search(len(heartbeats), func(i int) bool {
+		return !heartbeats[i].SentAt.Before(from)
+	})
+	last := sort.Search(len(heartbeats), func(i int) bool {
+		return !heartbeats[i].SentAt.Before(to)
+	})
+	return heartbeats[first:max(first, last)]
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSeriesServer returns a server with a single default model camera, registered when the
+// server started at registeredAt, that sent the given heartbeats
+func newSeriesServer(registeredAt time.Time, heartbeats ...time.Time) *Server {
+	devices := map[string]Device{"cam1": {ID: "cam1", RegisteredAt: registeredAt}}
+	deviceHeartbeats := map[string][]Heartbeat{"cam1": {}}
+	for _, sentAt := range heartbeats {
+		deviceHeartbeats["cam1"] = append(deviceHeartbeats["cam1"], Heartbeat{SentAt: sentAt, ReportedAt: sentAt, ReceivedAt: sentAt})
+	}
+	s := NewServer(devices, deviceHeartbeats, map[string][]DeviceStats{"cam1": {}}, Options{})
+	s.startedAt = registeredAt
+	return s
+}
+
+// everyMinute returns a heartbeat time for every minute from start until end
+func everyMinute(start, end time.Time) []time.Time {
+	var heartbeats []time.Time
+	for t := start; t.Before(end); t = t.Add(time.Minute) {
+		heartbeats = append(heartbeats, t)
+	}
+	return heartbeats
+}
+
+// checkUptimeBuckets checks the value of each bucket of an uptime series, nil for a gap
+func checkUptimeBuckets(t *testing.T, series SeriesGet, want []*float64) {
+	t.Helper()
+	if len(series.Points) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(series.Points), len(want))
+	}
+	for i, point := range series.Points {
+		switch {
+		case want[i] == nil && point.Value != nil:
+			t.Errorf("uptime bucket %d is %v, want nil", i, *point.Value)
+		case want[i] != nil && point.Value == nil:
+			t.Errorf("uptime bucket %d is nil, want %v", i, *want[i])
+		case want[i] != nil && *point.Value != *want[i]:
+			t.Errorf("uptime bucket %d is %v, want %v", i, *point.Value, *want[i])
+		}
+	}
+}
+
+func percent(value float64) *float64 {
+	return &value
+}
+
+func TestDeviceSeriesRange(t *testing.T) {
+	s := newSeriesServer(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	date := func(value string) *time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &parsed
+	}
+
+	tests := []struct {
+		name    string
+		from    *time.Time
+		to      *time.Time
+		step    time.Duration
+		buckets int
+		err     string
+	}{
+		{name: "whole range", from: date("2024-01-01T00:00:00Z"), to: date("2024-01-02T00:00:00Z"), step: time.Hour, buckets: 24},
+		{name: "partial last bucket", from: date("2024-01-01T00:00:00Z"), to: date("2024-01-01T02:30:00Z"), step: time.Hour, buckets: 3},
+		// 10000 minutes after the start
+		{name: "at the bucket limit", from: date("2024-01-01T00:00:00Z"), to: date("2024-01-07T22:40:00Z"), step: time.Minute, buckets: maxSeriesPoints},
+		{name: "over the bucket limit", from: date("2024-01-01T00:00:00Z"), to: date("2025-01-01T00:00:00Z"), step: time.Minute, err: "buckets"},
+		// the span saturates time.Duration, this used to overflow into a negative bucket count
+		{name: "span past the duration limit", from: date("0001-01-01T00:00:00Z"), to: date("9999-01-01T00:00:00Z"), step: time.Hour, err: "too long"},
+		{name: "step past the span", from: date("2024-01-01T00:00:00Z"), to: date("2024-01-01T00:00:01Z"), step: 1<<63 - 1, buckets: 1},
+		{name: "to before from", from: date("2024-01-02T00:00:00Z"), to: date("2024-01-01T00:00:00Z"), step: time.Hour, err: "after from"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			series, err := s.deviceSeries(context.Background(), "cam1", SeriesUptime, test.step, test.from, test.to)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(series.Points) != test.buckets {
+				t.Errorf("got %d buckets, want %d", len(series.Points), test.buckets)
+			}
+		})
+	}
+}
+
+func TestDeviceSeriesEmptyBuckets(t *testing.T) {
+	// a default model device heartbeats once a minute, so a full hour is 60 heartbeats
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// nothing in the second hour, half the heartbeats in the third
+	heartbeats := everyMinute(start, start.Add(time.Hour))
+	heartbeats = append(heartbeats, everyMinute(start.Add(2*time.Hour), start.Add(150*time.Minute))...)
+	s := newSeriesServer(start, heartbeats...)
+	end := start.Add(3 * time.Hour)
+
+	uptime, err := s.deviceSeries(context.Background(), "cam1", SeriesUptime, time.Hour, &start, &end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUptimeBuckets(t, uptime, []*float64{percent(100), percent(0), percent(50)})
+
+	// upload_time has no uploads at all, so every bucket is a gap
+	uploadTime, err := s.deviceSeries(context.Background(), "cam1", SeriesUploadTime, time.Hour, &start, &end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, point := range uploadTime.Points {
+		if point.Value != nil {
+			t.Errorf("upload_time bucket %d is %v, want nil", i, *point.Value)
+		}
+	}
+}
+
+func TestDeviceSeriesPartialBuckets(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the range ends halfway through the last bucket, which only expects the heartbeats until then
+	s := newSeriesServer(start, everyMinute(start, start.Add(150*time.Minute))...)
+	end := start.Add(150 * time.Minute)
+	uptime, err := s.deviceSeries(context.Background(), "cam1", SeriesUptime, time.Hour, &start, &end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUptimeBuckets(t, uptime, []*float64{percent(100), percent(100), percent(100)})
+
+	// the device was registered halfway through the first bucket, so only the half after
+	// counts and the bucket before it has nothing due
+	registered := start.Add(90 * time.Minute)
+	s = newSeriesServer(registered, everyMinute(registered, start.Add(3*time.Hour))...)
+	end = start.Add(3 * time.Hour)
+	uptime, err = s.deviceSeries(context.Background(), "cam1", SeriesUptime, time.Hour, &start, &end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUptimeBuckets(t, uptime, []*float64{nil, percent(100), percent(100)})
+
+	// the last bucket hasn't finished yet, so the heartbeats after now aren't missed yet
+	now := time.Now().UTC()
+	hour := now.Truncate(time.Hour).Add(-time.Hour)
+	s = newSeriesServer(hour, everyMinute(hour, now)...)
+	end = hour.Add(2 * time.Hour)
+	uptime, err = s.deviceSeries(context.Background(), "cam1", SeriesUptime, time.Hour, &hour, &end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUptimeBuckets(t, uptime, []*float64{percent(100), percent(100)})
+}
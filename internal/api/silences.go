@@ -0,0 +1,307 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"fleetsy/pkg/api"
+)
+
+// states a silence can be in, worked out from its start and end
+const (
+	SilencePending = "pending" // starts in the future
+	SilenceActive  = "active"
+	SilenceExpired = "expired"
+)
+
+// ErrSilenceNotFound is returned when a silence id doesn't exist
+var ErrSilenceNotFound = errors.New("silence not found")
+
+// only the most recent silences are kept, expired ones are dropped first
+const maxSilences = 1000
+
+// struct for the silence matchers, compared with an alert's labels
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"is_regex,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// struct for the silence store
+type Silence struct {
+	ID        string    `json:"id"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	State     string    `json:"state"` // filled in when the silence is returned
+}
+
+// struct for the incoming silence POST requests
+type SilencePost struct {
+	Matchers  []Matcher  `json:"matchers"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    time.Time  `json:"ends_at"`
+	CreatedBy string     `json:"created_by"`
+	Reason    string     `json:"reason"`
+}
+
+// response struct for the silences GET requests
+type SilencesGet struct {
+	Silences []Silence `json:"silences"`
+}
+
+// struct for the incoming acknowledge POST requests
+type AcknowledgePost struct {
+	By string `json:"by"`
+}
+
+// (GET /silences)
+func (s *Server) GetSilences(w http.ResponseWriter, r *http.Request, params api.GetSilencesParams) {
+	if params.State != nil {
+		switch *params.State {
+		case SilencePending, SilenceActive, SilenceExpired:
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("state must be %s, %s or %s", SilencePending, SilenceActive, SilenceExpired))
+			return
+		}
+	}
+
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	now := time.Now().UTC()
+	response := SilencesGet{Silences: []Silence{}}
+	for i := len(s.silenceOrder) - 1; i >= 0; i-- {
+		silence := s.silences[s.silenceOrder[i]].withState(now)
+		if params.State != nil && silence.State != *params.State {
+			continue
+		}
+		response.Silences = append(response.Silences, silence)
+	}
+	writeJSON(w, response)
+}
+
+// (POST /silences)
+func (s *Server) PostSilences(w http.ResponseWriter, r *http.Request) {
+	var newData SilencePost
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	silence, err := s.addSilence(newData)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(silence)
+}
+
+// (GET /silences/{silence})
+func (s *Server) GetSilencesSilence(w http.ResponseWriter, r *http.Request, silence api.SilencePathParam) {
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	found, ok := s.silences[silence]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Silence not found")
+		return
+	}
+	writeJSON(w, found.withState(time.Now().UTC()))
+}
+
+// (DELETE /silences/{silence})
+func (s *Server) DeleteSilencesSilence(w http.ResponseWriter, r *http.Request, silence api.SilencePathParam) {
+	if err := s.expireSilence(silence); err != nil {
+		writeError(w, http.StatusNotFound, "Silence not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (POST /alerts/{alert}/acknowledge)
+func (s *Server) PostAlertsAlertAcknowledge(w http.ResponseWriter, r *http.Request, alert api.AlertPathParam) {
+	var newData AcknowledgePost
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if newData.By == "" {
+		writeError(w, http.StatusBadRequest, "by is required")
+		return
+	}
+
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	found, ok := s.alerts[alert]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Alert not found")
+		return
+	}
+	if found.State != AlertFiring {
+		writeError(w, http.StatusBadRequest, "only firing alerts can be acknowledged")
+		return
+	}
+	// acknowledging twice keeps the first acknowledgement
+	if found.AcknowledgedAt == nil {
+		now := time.Now().UTC()
+		found.AcknowledgedAt = &now
+		found.AcknowledgedBy = newData.By
+	}
+	writeJSON(w, found)
+}
+
+// addSilence validates a silence and adds it to the store
+func (s *Server) addSilence(newData SilencePost) (Silence, error) {
+	if len(newData.Matchers) == 0 {
+		return Silence{}, errors.New("a silence needs at least one matcher")
+	}
+	if err := compileMatchers(newData.Matchers); err != nil {
+		return Silence{}, err
+	}
+	if newData.CreatedBy == "" || newData.Reason == "" {
+		return Silence{}, errors.New("created_by and reason are required")
+	}
+
+	now := time.Now().UTC()
+	startsAt := now
+	if newData.StartsAt != nil {
+		startsAt = newData.StartsAt.UTC()
+	}
+	endsAt := newData.EndsAt.UTC()
+	if !endsAt.After(startsAt) || !endsAt.After(now) {
+		return Silence{}, errors.New("ends_at must be after starts_at and in the future")
+	}
+
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	s.nextSilenceID++
+	silence := &Silence{
+		ID:        strconv.Itoa(s.nextSilenceID),
+		Matchers:  newData.Matchers,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: newData.CreatedBy,
+		Reason:    newData.Reason,
+		CreatedAt: now,
+	}
+	s.silences[silence.ID] = silence
+	s.silenceOrder = append(s.silenceOrder, silence.ID)
+	s.trimSilences(now)
+	return silence.withState(now), nil
+}
+
+// expireSilence ends a silence now.  It's kept so the silence history isn't lost.
+func (s *Server) expireSilence(id string) error {
+	s.alertMutex.Lock()
+	defer s.alertMutex.Unlock()
+
+	silence, found := s.silences[id]
+	if !found {
+		return ErrSilenceNotFound
+	}
+	now := time.Now().UTC()
+	if silence.EndsAt.After(now) {
+		silence.EndsAt = now
+	}
+	// a silence that hadn't started yet never will
+	if silence.StartsAt.After(now) {
+		silence.StartsAt = now
+	}
+	return nil
+}
+
+// trimSilences drops the oldest expired silences once there are too many.
+// The caller must hold the alert lock.
+func (s *Server) trimSilences(now time.Time) {
+	excess := len(s.silenceOrder) - maxSilences
+	if excess <= 0 {
+		return
+	}
+	s.silenceOrder = slices.DeleteFunc(s.silenceOrder, func(id string) bool {
+		if excess > 0 && !s.silences[id].EndsAt.After(now) {
+			delete(s.silences, id)
+			excess--
+			return true
+		}
+		return false
+	})
+}
+
+// silencedBy returns the ids of the active silences matching a set of labels, oldest first.
+// The caller must hold the alert lock.
+func (s *Server) silencedBy(labels map[string]string, now time.Time) []string {
+	var ids []string
+	for _, id := range s.silenceOrder {
+		silence := s.silences[id]
+		if silence.withState(now).State == SilenceActive && matchLabels(silence.Matchers, labels) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// withState returns a copy of the silence with its state filled in
+func (silence Silence) withState(now time.Time) Silence {
+	switch {
+	case now.Before(silence.StartsAt):
+		silence.State = SilencePending
+	case now.Before(silence.EndsAt):
+		silence.State = SilenceActive
+	default:
+		silence.State = SilenceExpired
+	}
+	return silence
+}
+
+// compileMatchers checks the matchers and compiles their regexes
+func compileMatchers(matchers []Matcher) error {
+	for i, matcher := range matchers {
+		if matcher.Name == "" {
+			return errors.New("matchers need a name")
+		}
+		if matcher.IsRegex {
+			regex, err := regexp.Compile("^(?:" + matcher.Value + ")$")
+			if err != nil {
+				return fmt.Errorf("matcher %s has an invalid regex: %w", matcher.Name, err)
+			}
+			matchers[i].regex = regex
+		}
+	}
+	return nil
+}
+
+// matchLabels reports whether every matcher matches the labels.  A missing label is empty.
+func matchLabels(matchers []Matcher, labels map[string]string) bool {
+	for _, matcher := range matchers {
+		value := labels[matcher.Name]
+		if matcher.regex != nil {
+			if !matcher.regex.MatchString(value) {
+				return false
+			}
+		} else if value != matcher.Value {
+			return false
+		}
+	}
+	return true
+}
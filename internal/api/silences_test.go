@@ -0,0 +1,144 @@
+package api
+
+import (
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fleetsy/pkg/api"
+)
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"rule": "offline", "device_id": "cam1", "model": "camera", "site": "site-a"}
+
+	tests := []struct {
+		name     string
+		matchers []Matcher
+		want     bool
+	}{
+		{name: "equal", matchers: []Matcher{{Name: "device_id", Value: "cam1"}}, want: true},
+		{name: "not equal", matchers: []Matcher{{Name: "device_id", Value: "cam2"}}, want: false},
+		{name: "every matcher has to match", matchers: []Matcher{{Name: "device_id", Value: "cam1"}, {Name: "site", Value: "site-b"}}, want: false},
+		{name: "equal is not a prefix", matchers: []Matcher{{Name: "device_id", Value: "cam"}}, want: false},
+		{name: "regex", matchers: []Matcher{{Name: "device_id", Value: "cam[0-9]+", IsRegex: true}}, want: true},
+		{name: "regex alternatives", matchers: []Matcher{{Name: "site", Value: "site-b|site-a", IsRegex: true}}, want: true},
+		// the regex has to match the whole value
+		{name: "regex is anchored", matchers: []Matcher{{Name: "device_id", Value: "cam", IsRegex: true}}, want: false},
+		{name: "regex alternatives are anchored", matchers: []Matcher{{Name: "device_id", Value: "x|cam", IsRegex: true}}, want: false},
+		// a missing label is empty
+		{name: "missing label equal to empty", matchers: []Matcher{{Name: "group", Value: ""}}, want: true},
+		{name: "missing label", matchers: []Matcher{{Name: "group", Value: "lobby"}}, want: false},
+		{name: "missing label matching regex", matchers: []Matcher{{Name: "group", Value: ".*", IsRegex: true}}, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := compileMatchers(test.matchers); err != nil {
+				t.Fatal(err)
+			}
+			if got := matchLabels(test.matchers, labels); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompileMatchersErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		matchers []Matcher
+	}{
+		{name: "no name", matchers: []Matcher{{Value: "cam1"}}},
+		{name: "invalid regex", matchers: []Matcher{{Name: "device_id", Value: "cam(", IsRegex: true}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := compileMatchers(test.matchers); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
+
+func TestSilencedBy(t *testing.T) {
+	server := NewServer(map[string]Device{}, map[string][]Heartbeat{}, map[string][]DeviceStats{}, Options{})
+	now := time.Now().UTC()
+	labels := map[string]string{"rule": "offline", "device_id": "cam1"}
+	silence := func(matcher Matcher, startsAt *time.Time, endsAt time.Time) string {
+		t.Helper()
+		added, err := server.addSilence(SilencePost{Matchers: []Matcher{matcher}, StartsAt: startsAt, EndsAt: endsAt, CreatedBy: "test", Reason: "test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return added.ID
+	}
+	later := now.Add(time.Hour)
+
+	active := silence(Matcher{Name: "device_id", Value: "cam1"}, nil, now.Add(2*time.Hour))
+	regex := silence(Matcher{Name: "rule", Value: "off.*", IsRegex: true}, nil, now.Add(2*time.Hour))
+	silence(Matcher{Name: "device_id", Value: "cam2"}, nil, now.Add(2*time.Hour))
+	pending := silence(Matcher{Name: "device_id", Value: "cam1"}, &later, now.Add(2*time.Hour))
+	ending := silence(Matcher{Name: "device_id", Value: "cam1"}, nil, now.Add(30*time.Minute))
+	expired := silence(Matcher{Name: "device_id", Value: "cam1"}, nil, now.Add(2*time.Hour))
+	if err := server.expireSilence(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []string
+	}{
+		{name: "now", at: now.Add(time.Second), want: []string{active, regex, ending}},
+		// the pending silence has started and the shorter one has run out
+		{name: "in an hour", at: now.Add(time.Hour), want: []string{active, regex, pending}},
+		{name: "all expired", at: now.Add(2 * time.Hour), want: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server.alertMutex.Lock()
+			got := server.silencedBy(labels, test.at)
+			server.alertMutex.Unlock()
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSilenceAfterEvaluation(t *testing.T) {
+	server := NewServer(map[string]Device{}, map[string][]Heartbeat{}, map[string][]DeviceStats{}, Options{})
+	now := time.Now().UTC()
+	server.alerts["1"] = &Alert{
+		ID: "1", Rule: "offline", DeviceID: "cam1", State: AlertFiring,
+		Labels:  map[string]string{"rule": "offline", "device_id": "cam1"},
+		FiredAt: &now, notified: true,
+	}
+	server.alertOrder = append(server.alertOrder, "1")
+	if firing := server.firingAlerts(now); len(firing) != 1 {
+		t.Fatalf("got %d firing alerts before the silence, want 1", len(firing))
+	}
+
+	// silenced between evaluations
+	if _, err := server.addSilence(SilencePost{
+		Matchers: []Matcher{{Name: "device_id", Value: "cam1"}}, EndsAt: now.Add(time.Hour), CreatedBy: "test", Reason: "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the silence is reported without touching the stored alert
+	w := httptest.NewRecorder()
+	server.GetAlerts(w, httptest.NewRequest("GET", "/alerts", nil), api.GetAlertsParams{})
+	if body := w.Body.String(); !strings.Contains(body, `"silenced_by":["1"]`) {
+		t.Errorf("got %s, want the alert silenced by 1", body)
+	}
+	if silencedBy := server.alerts["1"].SilencedBy; silencedBy != nil {
+		t.Errorf("GET /alerts stored silenced_by %v", silencedBy)
+	}
+
+	// and Alertmanager is no longer told the alert is firing
+	if firing := server.firingAlerts(time.Now().UTC()); len(firing) != 0 {
+		t.Errorf("got %d firing alerts after the silence, want 0", len(firing))
+	}
+}
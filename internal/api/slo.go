@@ -0,0 +1,307 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"fleetsy/internal/rules"
+	"fleetsy/pkg/api"
+)
+
+// states an SLO can be in
+const (
+	SLOStatusOK       = "ok"
+	SLOStatusAtRisk   = "at_risk"
+	SLOStatusBreached = "breached"
+)
+
+// ErrSLONotFound is returned when an SLO id hasn't been registered
+var ErrSLONotFound = errors.New("slo not found")
+
+const (
+	// this id would be shadowed by the at-risk listing
+	reservedSLOID = "at-risk"
+	// windows longer than this are almost certainly a typo
+	maxSLOWindow = 400 * 24 * time.Hour
+	// an SLO with less than this percentage of its error budget left is at risk
+	atRiskErrorBudget = 25.0
+)
+
+// the windows burn rates are reported over, and the rate at which each one puts an SLO at risk.
+// These are the multiwindow thresholds from the Google SRE workbook: 14.4 over an hour spends
+// 2% of a 30 day budget, 6 over six hours spends 5%.
+var sloBurnRateWindows = []struct {
+	name      string
+	window    time.Duration
+	threshold float64
+}{
+	{"1h", time.Hour, 14.4},
+	{"6h", 6 * time.Hour, 6},
+	{"24h", 24 * time.Hour, 3},
+}
+
+// struct for the SLO registry
+type SLO struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name,omitempty"`
+	Target  float64 `json:"target"` // uptime percentage, eg 99.5
+	Window  string  `json:"window"` // as it was given, eg 30d
+	Scope   string  `json:"scope"`
+	ScopeID string  `json:"scope_id"` // device id or group name
+
+	window time.Duration
+}
+
+// struct for the incoming SLO PUT requests
+type SLOPut struct {
+	Name    string  `json:"name"`
+	Target  float64 `json:"target"`
+	Window  string  `json:"window"`
+	Scope   string  `json:"scope"`
+	ScopeID string  `json:"scope_id"`
+}
+
+// response struct for a single SLO
+type SLOStatus struct {
+	SLO
+	Status               string             `json:"status"`
+	From                 time.Time          `json:"from"`
+	To                   time.Time          `json:"to"`
+	Attainment           float64            `json:"attainment"`
+	AllowedDowntime      string             `json:"allowed_downtime"`
+	Downtime             string             `json:"downtime"`
+	ErrorBudgetRemaining float64            `json:"error_budget_remaining"` // negative when overspent
+	BurnRates            map[string]float64 `json:"burn_rates"`
+}
+
+// response struct for the SLO list GET requests
+type SLOsGet struct {
+	SLOs []SLOStatus `json:"slos"`
+}
+
+// (GET /slos)
+func (s *Server) GetSlos(w http.ResponseWriter, r *http.Request, params api.GetSlosParams) {
+	writeJSON(w, SLOsGet{SLOs: s.sloStatuses(r.Context(), evaluationTime(params.To), false)})
+}
+
+// (GET /slos/at-risk)
+func (s *Server) GetSlosAtRisk(w http.ResponseWriter, r *http.Request, params api.GetSlosAtRiskParams) {
+	writeJSON(w, SLOsGet{SLOs: s.sloStatuses(r.Context(), evaluationTime(params.To), true)})
+}
+
+// (GET /slos/{slo})
+func (s *Server) GetSlosSlo(w http.ResponseWriter, r *http.Request, slo api.SLOPathParam, params api.GetSlosSloParams) {
+	objective, found := s.slo(slo)
+	if !found {
+		writeError(w, http.StatusNotFound, "SLO not found")
+		return
+	}
+
+	writeJSON(w, s.sloStatus(r.Context(), objective, evaluationTime(params.To)))
+}
+
+// (PUT /slos/{slo})
+func (s *Server) PutSlosSlo(w http.ResponseWriter, r *http.Request, slo api.SLOPathParam) {
+	var newData SLOPut
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	objective := SLO{
+		ID:      slo,
+		Name:    newData.Name,
+		Target:  newData.Target,
+		Window:  newData.Window,
+		Scope:   newData.Scope,
+		ScopeID: newData.ScopeID,
+	}
+	if err := s.registerSLO(objective); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (DELETE /slos/{slo})
+func (s *Server) DeleteSlosSlo(w http.ResponseWriter, r *http.Request, slo api.SLOPathParam) {
+	s.sloMutex.Lock()
+	defer s.sloMutex.Unlock()
+
+	if _, found := s.slos[slo]; !found {
+		writeError(w, http.StatusNotFound, "SLO not found")
+		return
+	}
+	delete(s.slos, slo)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// registerSLO validates an SLO and adds or replaces it in the registry
+func (s *Server) registerSLO(objective SLO) error {
+	if objective.ID == reservedSLOID {
+		return fmt.Errorf("%s can't be used as an SLO id", reservedSLOID)
+	}
+	if objective.Target <= 0 || objective.Target >= 100 {
+		return errors.New("target must be a percentage between 0 and 100, eg 99.5")
+	}
+	window, err := parseSLOWindow(objective.Window)
+	if err != nil {
+		return err
+	}
+	objective.window = window
+
+	if err := s.validateScope(objective.Scope, objective.ScopeID); err != nil {
+		return err
+	}
+
+	s.sloMutex.Lock()
+	defer s.sloMutex.Unlock()
+
+	s.slos[objective.ID] = objective
+	return nil
+}
+
+// slo looks up an SLO in the registry
+func (s *Server) slo(id string) (SLO, bool) {
+	s.sloMutex.RLock()
+	defer s.sloMutex.RUnlock()
+
+	objective, found := s.slos[id]
+	return objective, found
+}
+
+// sloStatuses evaluates every SLO, sorted by id
+func (s *Server) sloStatuses(ctx context.Context, to time.Time, atRiskOnly bool) []SLOStatus {
+	// copy the registry so the device lock isn't taken while holding the slo lock
+	s.sloMutex.RLock()
+	objectives := make([]SLO, 0, len(s.slos))
+	for _, objective := range s.slos {
+		objectives = append(objectives, objective)
+	}
+	s.sloMutex.RUnlock()
+	slices.SortFunc(objectives, func(a, b SLO) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	statuses := []SLOStatus{}
+	for _, objective := range objectives {
+		status := s.sloStatus(ctx, objective, to)
+		if atRiskOnly && status.Status == SLOStatusOK {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// sloStatus works out how an SLO is doing over the window ending at to
+func (s *Server) sloStatus(ctx context.Context, objective SLO, to time.Time) SLOStatus {
+	status := SLOStatus{
+		SLO:       objective,
+		From:      to.Add(-objective.window),
+		To:        to,
+		BurnRates: make(map[string]float64),
+	}
+	// the fraction of the window the target allows to be down
+	budget := 1 - objective.Target/100
+
+	downtime, total := s.sloDowntime(ctx, objective, status.From, to)
+	// nothing was due yet, eg right after the server started, so nothing has been missed either
+	attainment := 1.0
+	if total > 0 {
+		attainment = 1 - float64(downtime)/float64(total)
+	}
+	allowed := time.Duration(budget * float64(total))
+	status.Attainment = attainment * 100
+	status.Downtime = downtime.String()
+	status.AllowedDowntime = allowed.String()
+	if allowed > 0 {
+		status.ErrorBudgetRemaining = float64(allowed-downtime) / float64(allowed) * 100
+	} else if total == 0 {
+		status.ErrorBudgetRemaining = 100
+	}
+
+	status.Status = SLOStatusOK
+	if status.ErrorBudgetRemaining < atRiskErrorBudget {
+		status.Status = SLOStatusAtRisk
+	}
+	for _, burnWindow := range sloBurnRateWindows {
+		// a burn window longer than the slo window would just repeat the attainment
+		if burnWindow.window > objective.window {
+			continue
+		}
+		downtime, total := s.sloDowntime(ctx, objective, to.Add(-burnWindow.window), to)
+		if total == 0 {
+			continue
+		}
+		burnRate := float64(downtime) / float64(total) / budget
+		status.BurnRates[burnWindow.name] = burnRate
+		if burnRate >= burnWindow.threshold {
+			status.Status = SLOStatusAtRisk
+		}
+	}
+	if status.Attainment < objective.Target {
+		status.Status = SLOStatusBreached
+	}
+	return status
+}
+
+// sloDowntime adds up the time the devices an SLO covers were down between from and to, along
+// with the total device time.  A device is down for every heartbeat interval it missed, from
+// when the server could first have heard from it.
+func (s *Server) sloDowntime(ctx context.Context, objective SLO, from, to time.Time) (downtime, total time.Duration) {
+	ctx, span := startStoreSpan(ctx, "slo_downtime", attribute.String("fleetsy.slo", objective.ID))
+	defer span.End()
+
+	// lock the mutex for reading
+	s.rlockDevices(ctx)
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.RUnlock()
+
+	for deviceId, device := range s.devices {
+		if !inScope(deviceId, device, objective.Scope, objective.ScopeID) {
+			continue
+		}
+
+		interval := deviceTypeFor(device.Model).HeartbeatInterval()
+		observed, expected := countSlots(s.deviceHeartbeatMap[deviceId], s.heardSince(device, from), to, interval, s.options.UptimeTolerance)
+		downtime += time.Duration(expected-observed) * interval
+		total += time.Duration(expected) * interval
+	}
+	return downtime, total
+}
+
+// parseSLOWindow parses a window like 30d or 168h
+func parseSLOWindow(value string) (time.Duration, error) {
+	// the same parser as the alert rules, which rejects a day count that would overflow
+	window, err := rules.ParseDuration(value)
+	if err != nil {
+		return 0, errors.New("window must be a duration. Eg: 30d, 168h")
+	}
+
+	if window < time.Hour || window > maxSLOWindow {
+		return 0, errors.New("window must be between 1h and 400d")
+	}
+	return window, nil
+}
+
+// evaluationTime returns the end of the window to evaluate, now unless the request asked otherwise
+func evaluationTime(to *time.Time) time.Time {
+	if to != nil {
+		return to.UTC()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,126 @@
+package api
+
+import "time"
+
+// calculateUptime returns the uptime percentage for a device's heartbeats over the span from
+// the first to the last one.  This is the span uptime mode, see uptime.go for the others.
+// interval is how often the device is expected to send a heartbeat.
+func calculateUptime(deviceHeartbeats []Heartbeat, interval time.Duration) float32 {
+	// check array length first
+	if len(deviceHeartbeats) == 0 {
+		return 0.0
+	}
+
+	sumHeartbeats := len(deviceHeartbeats)
+	firstTimestamp := deviceHeartbeats[0].SentAt
+	// assuming that all heartbeats were received in chronological order
+	lastTimestamp := deviceHeartbeats[len(deviceHeartbeats)-1].SentAt
+	// the devices are expected to send one heartbeat every interval, eg every minute
+	// so we need to use the number of intervals to calculate the uptime properly
+	// subtract the timestamps and convert
+	diff := float64(lastTimestamp.Sub(firstTimestamp)) / float64(interval)
+	// a single heartbeat has no span to measure over, dividing by it would give +Inf or NaN
+	// which can't be encoded as json.  The device has sent everything expected of it so far.
+	if diff <= 0 {
+		return 100
+	}
+	// now calculate the uptime percentage
+	// n heartbeats sent on time only span n-1 intervals, so cap it rather than report over 100%
+	return min((float32(sumHeartbeats)/float32(diff))*100, 100)
+}
+
+// calculateAvgUploadTime returns the average upload time as a duration string.
+// Records without an upload time are left out, the same as the sketches, empty if there are none.
+func calculateAvgUploadTime(deviceStats []DeviceStats) string {
+	var totalSeconds float64 = 0
+	var uploads int = 0
+	for _, stats := range deviceStats {
+		if stats.UploadTime <= 0 {
+			continue
+		}
+		// convert to time.Duration to make some of this easier
+		dur := time.Duration(stats.UploadTime)
+		totalSeconds += dur.Seconds()
+		uploads++
+	}
+	if uploads == 0 {
+		return ""
+	}
+	avg := totalSeconds / float64(uploads)
+	// time.Duration works in nanoseconds, so we need to convert seconds as part of this
+	// there are 1e9 nanoseconds in every second
+	return time.Duration(avg * 1e9).String()
+}
+
+// calculateThroughput returns the average upload throughput in MB/s.
+// Only uploads that reported their size are counted, nil if there are none.
+func calculateThroughput(deviceStats []DeviceStats) *float64 {
+	var totalBytes int64 = 0
+	var totalSeconds float64 = 0
+	for _, stats := range deviceStats {
+		if stats.BytesUploaded <= 0 || stats.UploadTime <= 0 {
+			continue
+		}
+		totalBytes += stats.BytesUploaded
+		totalSeconds += time.Duration(stats.UploadTime).Seconds()
+	}
+	if totalSeconds == 0 {
+		return nil
+	}
+
+	// total bytes over total time so a few tiny uploads don't skew the result
+	throughput := float64(totalBytes) / 1e6 / totalSeconds
+	return &throughput
+}
+
+// calculateFailureRate returns the percentage of uploads that failed.
+// Only uploads that reported a result code are counted, nil if there are none.
+func calculateFailureRate(deviceStats []DeviceStats) *float64 {
+	reported := 0
+	failed := 0
+	for _, stats := range deviceStats {
+		if stats.ResultCode == nil {
+			continue
+		}
+		reported++
+		if *stats.ResultCode != 0 {
+			failed++
+		}
+	}
+	if reported == 0 {
+		return nil
+	}
+
+	failureRate := float64(failed) / float64(reported) * 100
+	return &failureRate
+}
+
+// countSlots works out how many heartbeats the device should have sent between from and to,
+// one every interval starting at from, and how many of those it actually sent.  Each expected
+// heartbeat has a slot centred on the time it was due, so a little jitter either way still
+// counts, and extra heartbeats in a slot don't count twice so the result can never pass 100%.
+// A heartbeat isn't expected until tolerance after it was due, so a device isn't marked down
+// for the heartbeat that's on its way right now.
+func countSlots(deviceHeartbeats []Heartbeat, from, to time.Time, interval, tolerance time.Duration) (observed, expected int) {
+	span := to.Sub(from) - tolerance
+	if span <= 0 {
+		return 0, 0
+	}
+	// every heartbeat due strictly before to - tolerance
+	expected = int((span + interval - 1) / interval)
+
+	seen := make([]bool, expected)
+	for _, heartbeat := range deviceHeartbeats {
+		offset := heartbeat.SentAt.Sub(from) + interval/2
+		if offset < 0 {
+			continue
+		}
+		slot := int(offset / interval)
+		if slot >= expected || seen[slot] {
+			continue
+		}
+		seen[slot] = true
+		observed++
+	}
+	return observed, expected
+}
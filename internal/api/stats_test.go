@@ -0,0 +1,31 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateAvgUploadTime(t *testing.T) {
+	tests := []struct {
+		name        string
+		uploadTimes []time.Duration
+		want        string
+	}{
+		{name: "no stats", want: ""},
+		{name: "average", uploadTimes: []time.Duration{10 * time.Second, 20 * time.Second}, want: "15s"},
+		// records without an upload time, like a doorbell's ring, don't drag the average down
+		{name: "without upload times", uploadTimes: []time.Duration{10 * time.Second, 0, 20 * time.Second, 0}, want: "15s"},
+		{name: "only without upload times", uploadTimes: []time.Duration{0, 0}, want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var deviceStats []DeviceStats
+			for _, uploadTime := range test.uploadTimes {
+				deviceStats = append(deviceStats, DeviceStats{UploadTime: int64(uploadTime)})
+			}
+			if got := calculateAvgUploadTime(deviceStats); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrDeviceNotFound is returned when a device id isn't in the device db
+var ErrDeviceNotFound = errors.New("device not found")
+
+// where a heartbeat came from, which decides what appendHeartbeat checks before storing it
+type heartbeatSource int
+
+const (
+	heartbeatFromDevice heartbeatSource = iota // timestamped by the device
+	heartbeatFromUDP                           // timestamped by the device, and could be a replayed datagram
+	heartbeatFromServer                        // the device answered over its websocket, timestamped by the server
+)
+
+// addHeartbeat appends a heartbeat to the device's history.
+// This is shared by every ingestion path so they all feed the same store.
+func (s *Server) addHeartbeat(ctx context.Context, deviceId string, sentAt time.Time) error {
+	return s.appendHeartbeat(ctx, deviceId, sentAt, heartbeatFromDevice, 0)
+}
+
+// addUDPHeartbeat is addHeartbeat for UDP datagrams, which can be captured and replayed.  The
+// heartbeat is only added if its reported time is after the last one accepted over UDP for the
+// device, otherwise errStaleHeartbeat is returned.
+func (s *Server) addUDPHeartbeat(ctx context.Context, deviceId string, sentAt time.Time) error {
+	return s.appendHeartbeat(ctx, deviceId, sentAt, heartbeatFromUDP, 0)
+}
+
+// addServerHeartbeat records a heartbeat for a device that showed it's there without sending
+// one, by connecting its websocket or answering a ping.  It's timestamped with the server clock
+// and marked so the device's clock estimate leaves it out.  Nothing is stored if a heartbeat was
+// already received within the last interval, the device sent its own heartbeat for that slot,
+// but the device is still marked as seen.
+func (s *Server) addServerHeartbeat(ctx context.Context, deviceId string, interval time.Duration) error {
+	return s.appendHeartbeat(ctx, deviceId, time.Time{}, heartbeatFromServer, interval)
+}
+
+// appendHeartbeat does the work for addHeartbeat, addUDPHeartbeat and addServerHeartbeat.  The
+// checks are made under the same write lock as the append, so another heartbeat can't land in
+// between.
+func (s *Server) appendHeartbeat(ctx context.Context, deviceId string, sentAt time.Time, source heartbeatSource, interval time.Duration) (err error) {
+	ctx, span := startStoreSpan(ctx, "add_heartbeat", attribute.String("fleetsy.device_id", deviceId))
+	defer func() { endSpan(span, err) }()
+
+	// stamp the record with our own clock so device clock problems can be spotted
+	receivedAt := time.Now().UTC()
+	if source == heartbeatFromServer {
+		sentAt = receivedAt
+	}
+	heartbeat := Heartbeat{
+		ReportedAt: sentAt,
+		ReceivedAt: receivedAt,
+	}
+	if source == heartbeatFromServer {
+		heartbeat.SentAt = sentAt
+		heartbeat.ServerTimed = true
+	} else {
+		heartbeat.SentAt, heartbeat.Skewed = s.checkClockSkew(sentAt, receivedAt)
+	}
+
+	// lock the mutex for writing
+	s.lockDevices(ctx)
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.Unlock()
+	// validate the device exists in the db
+	heartbeats, found := s.deviceHeartbeatMap[deviceId]
+	if !found {
+		return ErrDeviceNotFound
+	}
+	switch source {
+	case heartbeatFromUDP:
+		if last, found := s.lastUDPHeartbeat[deviceId]; found && !sentAt.After(last) {
+			return errStaleHeartbeat
+		}
+		s.lastUDPHeartbeat[deviceId] = sentAt
+	case heartbeatFromServer:
+		if len(heartbeats) > 0 && receivedAt.Sub(heartbeats[len(heartbeats)-1].ReceivedAt) < interval {
+			s.presenceHeartbeat(deviceId, receivedAt)
+			return nil
+		}
+	}
+
+	s.deviceHeartbeatMap[deviceId] = append(heartbeats, heartbeat)
+	s.checkHeartbeatAnomaly(deviceId, heartbeat)
+	s.presenceHeartbeat(deviceId, receivedAt)
+	s.metrics.ingested.WithLabelValues("heartbeat").Inc()
+	return nil
+}
+
+// device returns the device's details from the device db
+func (s *Server) device(deviceId string) (Device, bool) {
+	s.rlockDevices(context.Background())
+	defer s.deviceMutex.RUnlock()
+
+	device, found := s.devices[deviceId]
+	return device, found
+}
+
+// addStats appends an upload stats record to the device's history
+func (s *Server) addStats(ctx context.Context, deviceId string, stats DeviceStats) (err error) {
+	ctx, span := startStoreSpan(ctx, "add_stats", attribute.String("fleetsy.device_id", deviceId))
+	defer func() { endSpan(span, err) }()
+
+	// stamp the record with our own clock so device clock problems can be spotted
+	stats.ReportedAt = stats.SentAt
+	stats.ReceivedAt = time.Now().UTC()
+	stats.SentAt, stats.Skewed = s.checkClockSkew(stats.ReportedAt, stats.ReceivedAt)
+
+	// lock the mutex for writing
+	s.lockDevices(ctx)
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.Unlock()
+	// validate the device exists in the db
+	if _, found := s.deviceStatsMap[deviceId]; !found {
+		return ErrDeviceNotFound
+	}
+
+	s.deviceStatsMap[deviceId] = append(s.deviceStatsMap[deviceId], stats)
+	// keep the distribution up to date so percentiles don't need the whole history
+	if stats.UploadTime > 0 {
+		s.uploadTimeSketches[deviceId].Add(float64(stats.UploadTime))
+		s.notifyUploadTime(deviceId, stats)
+	}
+	s.checkUploadTimeAnomaly(deviceId, stats)
+	s.metrics.ingested.WithLabelValues("stats").Inc()
+	return nil
+}
+
+// addMetricSamples appends validated telemetry samples to the device's history
+func (s *Server) addMetricSamples(ctx context.Context, deviceId string, samples []validMetricSample) (err error) {
+	ctx, span := startStoreSpan(ctx, "add_metric_samples", attribute.String("fleetsy.device_id", deviceId), attribute.Int("fleetsy.samples", len(samples)))
+	defer func() { endSpan(span, err) }()
+
+	receivedAt := time.Now().UTC()
+
+	// lock the mutex for writing
+	s.lockDevices(ctx)
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.Unlock()
+	// validate the device exists in the db
+	metrics, found := s.deviceMetricsMap[deviceId]
+	if !found {
+		return ErrDeviceNotFound
+	}
+
+	for _, sample := range samples {
+		sample.sample.ReceivedAt = receivedAt
+		metrics[sample.name] = append(metrics[sample.name], sample.sample)
+	}
+	s.metrics.ingested.WithLabelValues("metric_sample").Add(float64(len(samples)))
+	return nil
+}
+
+// deviceStats computes the stats response for a single device.
+// Every API surface goes through here so they all report the same numbers.
+func (s *Server) deviceStats(ctx context.Context, deviceId string) (_ StatsGet, err error) {
+	ctx, span := startStoreSpan(ctx, "device_stats", attribute.String("fleetsy.device_id", deviceId))
+	defer func() { endSpan(span, err) }()
+
+	// lock the mutex for reading
+	s.rlockDevices(ctx)
+	// defer to guarantee it's unlocked later
+	defer s.deviceMutex.RUnlock()
+
+	// validate the device exists in the db
+	deviceHeartbeats, heartbeatFound := s.deviceHeartbeatMap[deviceId]
+	deviceStats, statsFound := s.deviceStatsMap[deviceId]
+	if !heartbeatFound || !statsFound {
+		return StatsGet{}, ErrDeviceNotFound
+	}
+
+	// the device type decides what the stats mean for this kind of device
+	device := s.devices[deviceId]
+	response := deviceTypeFor(device.Model).Stats(deviceHeartbeats, deviceStats)
+	response.Uptime = s.deviceUptime(device, deviceHeartbeats, nil, nil)
+	response.SkewedRecords = countSkewedRecords(deviceHeartbeats, deviceStats)
+	response.UploadTimeDistribution = uploadTimeDistribution(s.uploadTimeSketches[deviceId])
+
+	clock := estimateClock(deviceHeartbeats, deviceTypeFor(device.Model).HeartbeatInterval())
+	if clock.Samples > 0 {
+		response.ClockOffset = clock.Offset.String()
+	}
+	response.ClockDriftPPM = clock.DriftPPM
+	return response, nil
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// where spans can be exported to
+const (
+	TraceExporterNone   = "none"
+	TraceExporterStdout = "stdout"
+	TraceExporterOTLP   = "otlp"
+)
+
+// the tracer for everything in the api package.  It goes through the global provider so
+// it picks up whatever StartTracing sets, and does nothing when tracing is off.
+var tracer = otel.Tracer("fleetsy/internal/api")
+
+// struct for the tracing options
+type TracingOptions struct {
+	Exporter     string  // none, stdout or otlp
+	OTLPEndpoint string  // url of the collector, eg http://localhost:4318 (the OTEL_EXPORTER_OTLP_* variables when empty)
+	SampleRatio  float64 // fraction of new traces to keep, traces started by a caller follow the caller's decision
+}
+
+// StartTracing sets up the global tracer provider and the W3C trace context propagator.
+// The returned function flushes any spans still waiting to be exported.
+func StartTracing(ctx context.Context, options TracingOptions) (func(context.Context) error, error) {
+	// incoming traceparent headers are always honoured so the trace ids still line up in the
+	// logs and downstream when nothing is exported
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if options.SampleRatio < 0 || options.SampleRatio > 1 {
+		return nil, errors.New("trace sample ratio must be between 0 and 1")
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch options.Exporter {
+	case TraceExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case TraceExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case TraceExporterOTLP:
+		var exporterOptions []otlptracehttp.Option
+		if options.OTLPEndpoint != "" {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithEndpointURL(options.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, exporterOptions...)
+	default:
+		return nil, fmt.Errorf("trace exporter must be %s, %s or %s", TraceExporterNone, TraceExporterStdout, TraceExporterOTLP)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	traceResource, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("fleetsy")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(traceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// TraceRequests starts a server span for every request, continuing the caller's trace when
+// it sends a traceparent header.  The span is named after the route pattern rather than the
+// path, the same as the request metrics, so device ids don't end up in span names.
+func TraceRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		// the route isn't known until the router has run, so the name is fixed up afterwards
+		ctx, span := tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+		))
+		defer span.End()
+		if requestId := middleware.GetReqID(ctx); requestId != "" {
+			span.SetAttributes(attribute.String("fleetsy.request_id", requestId))
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if routeContext := chi.RouteContext(ctx); routeContext != nil && routeContext.RoutePattern() != "" {
+			span.SetName(r.Method + " " + routeContext.RoutePattern())
+			span.SetAttributes(semconv.HTTPRoute(routeContext.RoutePattern()))
+			if deviceId := routeContext.URLParam("device_id"); deviceId != "" {
+				span.SetAttributes(attribute.String("fleetsy.device_id", deviceId))
+			}
+		}
+		status := ww.Status()
+		// handlers that only write a body never set the status explicitly
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		// client errors are the client's problem, only server errors mark the span as failed
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// startStoreSpan starts a span for an operation on the device store
+func startStoreSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "store."+operation, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error, if there was one, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceLock starts a span for the wait on the device lock and returns what ends it.  Locks
+// taken outside a traced request or store operation, like the background workers and the
+// metrics scrape, aren't traced since every one of them would be a trace of its own.
+func traceLock(ctx context.Context, mode string) func() {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return func() {}
+	}
+	_, span := tracer.Start(ctx, "lock.devices", trace.WithAttributes(attribute.String("fleetsy.lock_mode", mode)))
+	return func() { span.End() }
+}
+
+// traceId returns the id of the trace the context is part of, or "" when there isn't one
+func traceId(ctx context.Context) string {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	return ""
+}
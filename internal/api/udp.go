@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maximum size of a heartbeat datagram, anything bigger is dropped
+// a device id, a unix timestamp and a hex encoded sha256 fit easily
+const maxHeartbeatDatagram = 512
+
+var (
+	errMalformedDatagram = errors.New("malformed heartbeat datagram")
+	errBadSignature      = errors.New("invalid heartbeat signature")
+	errStaleHeartbeat    = errors.New("heartbeat is not newer than the last one received over UDP")
+	errSkewedHeartbeat   = errors.New("heartbeat timestamp is too far from the server clock")
+)
+
+// how far a datagram's timestamp can be from the server clock when Options.MaxClockSkew isn't set.
+// A captured datagram can only be replayed within this window, until a newer heartbeat arrives.
+const defaultUDPClockSkew = 5 * time.Minute
+
+// ListenHeartbeatUDP accepts compact heartbeats over UDP until ctx is cancelled.
+//
+// Each datagram is a single line of the form
+//
+//	<device_id>|<unix_seconds>|<hex hmac-sha256>
+//
+// where the signature is the HMAC-SHA256 of "<device_id>|<unix_seconds>" using
+// the shared secret. Valid heartbeats go into the same store as the HTTP endpoint.
+func (s *Server) ListenHeartbeatUDP(ctx context.Context, addr string, secret []byte) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+
+	// close the socket when we're told to stop so ReadFrom returns
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	slog.Info("UDP heartbeat listener is running", "addr", conn.LocalAddr().String())
+
+	buf := make([]byte, maxHeartbeatDatagram+1)
+	for {
+		n, remote, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if n > maxHeartbeatDatagram {
+			slog.Warn("dropping oversized heartbeat datagram", "remote", remote.String())
+			continue
+		}
+
+		if err := s.handleHeartbeatDatagram(ctx, buf[:n], secret); err != nil {
+			slog.Warn("dropping heartbeat datagram", "remote", remote.String(), "error", err)
+		}
+	}
+}
+
+// handleHeartbeatDatagram verifies a single datagram and records the heartbeat
+func (s *Server) handleHeartbeatDatagram(ctx context.Context, datagram []byte, secret []byte) (err error) {
+	// datagrams have nowhere to carry a traceparent, so each one starts a trace of its own
+	ctx, span := tracer.Start(ctx, "udp heartbeat", trace.WithSpanKind(trace.SpanKindServer), trace.WithNewRoot())
+	defer func() { endSpan(span, err) }()
+
+	deviceId, sentAt, err := parseHeartbeatDatagram(datagram, secret)
+	if err != nil {
+		return err
+	}
+
+	span.SetAttributes(attribute.String("fleetsy.device_id", deviceId))
+
+	// datagrams can be captured and replayed, so only accept heartbeats that are recent and move
+	// the device forward.  Unlike the other paths a skewed clock isn't just marked, a timestamp far
+	// in the future would block the device's later heartbeats.
+	maxSkew := s.options.MaxClockSkew
+	if maxSkew <= 0 {
+		maxSkew = defaultUDPClockSkew
+	}
+	if time.Since(sentAt).Abs() > maxSkew {
+		return errSkewedHeartbeat
+	}
+	return s.addUDPHeartbeat(ctx, deviceId, sentAt)
+}
+
+// parseHeartbeatDatagram splits and authenticates a datagram
+func parseHeartbeatDatagram(datagram []byte, secret []byte) (string, time.Time, error) {
+	// tolerate a trailing newline from tools like nc
+	datagram = bytes.TrimRight(datagram, "\r\n")
+
+	parts := bytes.Split(datagram, []byte("|"))
+	if len(parts) != 3 || len(parts[0]) == 0 {
+		return "", time.Time{}, errMalformedDatagram
+	}
+
+	signature, err := hex.DecodeString(string(parts[2]))
+	if err != nil {
+		return "", time.Time{}, errMalformedDatagram
+	}
+
+	// the signed payload is everything before the last separator
+	payload := datagram[:len(parts[0])+1+len(parts[1])]
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", time.Time{}, errBadSignature
+	}
+
+	seconds, err := strconv.ParseInt(string(parts[1]), 10, 64)
+	if err != nil {
+		return "", time.Time{}, errMalformedDatagram
+	}
+
+	return string(parts[0]), time.Unix(seconds, 0).UTC(), nil
+}
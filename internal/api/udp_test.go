@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var udpSecret = []byte("changeme")
+
+// signedDatagram builds a datagram the way a device would
+func signedDatagram(secret []byte, deviceId string, sentAt time.Time) []byte {
+	return signedPayload(secret, fmt.Sprintf("%s|%d", deviceId, sentAt.Unix()))
+}
+
+// signedPayload signs an arbitrary payload
+func signedPayload(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return []byte(payload + "|" + hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestParseHeartbeatDatagram(t *testing.T) {
+	sentAt := time.Unix(1759176000, 0).UTC()
+	valid := signedDatagram(udpSecret, "cam1", sentAt)
+
+	tests := []struct {
+		name     string
+		datagram []byte
+		err      error
+	}{
+		{name: "valid", datagram: valid},
+		{name: "trailing newline", datagram: append(valid, '\n')},
+		{name: "wrong secret", datagram: signedDatagram([]byte("guess"), "cam1", sentAt), err: errBadSignature},
+		{name: "device id changed", datagram: append([]byte("cam2"), valid[len("cam1"):]...), err: errBadSignature},
+		{name: "timestamp changed", datagram: []byte(fmt.Sprintf("cam1|%d|%s", sentAt.Unix()+60, valid[len(valid)-64:])), err: errBadSignature},
+		{name: "truncated signature", datagram: valid[:len(valid)-2], err: errBadSignature},
+		{name: "signature not hex", datagram: []byte("cam1|1759176000|not-hex"), err: errMalformedDatagram},
+		{name: "missing signature", datagram: []byte("cam1|1759176000"), err: errMalformedDatagram},
+		{name: "extra field", datagram: append([]byte("x|"), valid...), err: errMalformedDatagram},
+		{name: "empty device id", datagram: signedDatagram(udpSecret, "", sentAt), err: errMalformedDatagram},
+		// signed, but the timestamp still has to be a number
+		{name: "timestamp not a number", datagram: signedPayload(udpSecret, "cam1|yesterday"), err: errMalformedDatagram},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deviceId, got, err := parseHeartbeatDatagram(test.datagram, udpSecret)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if err == nil && (deviceId != "cam1" || !got.Equal(sentAt)) {
+				t.Errorf("got %s at %s, want cam1 at %s", deviceId, got, sentAt)
+			}
+		})
+	}
+}
+
+func TestHandleHeartbeatDatagram(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name         string
+		maxClockSkew time.Duration
+		// heartbeats already received over other paths
+		posted []time.Time
+		// datagrams sent one after the other, the last one is checked
+		sent []time.Time
+		err  error
+	}{
+		{name: "first heartbeat", sent: []time.Time{now}},
+		{name: "newer heartbeat", sent: []time.Time{now.Add(-time.Minute), now}},
+		{name: "replayed", sent: []time.Time{now, now}, err: errStaleHeartbeat},
+		{name: "older than the last one", sent: []time.Time{now, now.Add(-time.Minute)}, err: errStaleHeartbeat},
+		// a heartbeat posted over HTTP with a clock in the future doesn't block UDP
+		{name: "after a future HTTP heartbeat", posted: []time.Time{time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}, sent: []time.Time{now}},
+		{name: "after a newer HTTP heartbeat", posted: []time.Time{now.Add(time.Minute)}, sent: []time.Time{now}},
+		// the skew is enforced even when max-clock-skew isn't set
+		{name: "from the future", sent: []time.Time{now.Add(time.Hour)}, err: errSkewedHeartbeat},
+		{name: "captured long ago", sent: []time.Time{now.Add(-time.Hour)}, err: errSkewedHeartbeat},
+		{name: "within max-clock-skew", maxClockSkew: 2 * time.Hour, sent: []time.Time{now.Add(-time.Hour)}},
+		{name: "outside max-clock-skew", maxClockSkew: time.Minute, sent: []time.Time{now.Add(-2 * time.Minute)}, err: errSkewedHeartbeat},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewServer(map[string]Device{"cam1": {ID: "cam1"}}, map[string][]Heartbeat{"cam1": {}}, map[string][]DeviceStats{"cam1": {}},
+				Options{MaxClockSkew: test.maxClockSkew})
+			for _, sentAt := range test.posted {
+				if err := server.addHeartbeat(context.Background(), "cam1", sentAt); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var err error
+			for _, sentAt := range test.sent {
+				err = server.handleHeartbeatDatagram(context.Background(), signedDatagram(udpSecret, "cam1", sentAt), udpSecret)
+			}
+			if !errors.Is(err, test.err) {
+				t.Errorf("got error %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	t.Run("bad signature", func(t *testing.T) {
+		server := NewServer(map[string]Device{"cam1": {ID: "cam1"}}, map[string][]Heartbeat{"cam1": {}}, map[string][]DeviceStats{"cam1": {}}, Options{})
+		err := server.handleHeartbeatDatagram(context.Background(), signedDatagram([]byte("guess"), "cam1", now), udpSecret)
+		if !errors.Is(err, errBadSignature) {
+			t.Errorf("got error %v, want %v", err, errBadSignature)
+		}
+		if heartbeats := server.deviceHeartbeatMap["cam1"]; len(heartbeats) != 0 {
+			t.Errorf("stored %d heartbeats from a bad signature", len(heartbeats))
+		}
+	})
+
+	t.Run("unknown device", func(t *testing.T) {
+		server := NewServer(map[string]Device{}, map[string][]Heartbeat{}, map[string][]DeviceStats{}, Options{})
+		err := server.handleHeartbeatDatagram(context.Background(), signedDatagram(udpSecret, "cam9", now), udpSecret)
+		if !errors.Is(err, ErrDeviceNotFound) {
+			t.Errorf("got error %v, want %v", err, ErrDeviceNotFound)
+		}
+		if len(server.lastUDPHeartbeat) != 0 {
+			t.Errorf("tracked %v for an unknown device", server.lastUDPHeartbeat)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// ways uptime can be worked out, see Options.UptimeMode
+const (
+	// heartbeats over the span from the first to the last one.  This is the original formula and
+	// what the device simulator expects, but a device that stops sending heartbeats keeps its uptime.
+	UptimeModeSpan = "span"
+	// heartbeats sent over heartbeats expected from the first heartbeat until now
+	UptimeModeFirstHeartbeat = "first_heartbeat"
+	// heartbeats sent over heartbeats expected from when the device was registered until now
+	UptimeModeRegistration = "registration"
+)
+
+// UptimeModes is the list of valid uptime modes
+var UptimeModes = []string{UptimeModeSpan, UptimeModeFirstHeartbeat, UptimeModeRegistration}
+
+// ValidateUptimeMode checks an uptime mode from the command line
+func ValidateUptimeMode(mode string) error {
+	switch mode {
+	case UptimeModeSpan, UptimeModeFirstHeartbeat, UptimeModeRegistration:
+		return nil
+	default:
+		return fmt.Errorf("uptime mode must be one of %v", UptimeModes)
+	}
+}
+
+// deviceUptime works out a device's uptime percentage using the configured uptime mode.
+// from and to optionally limit it to a window, the heartbeats should already be limited
+// to the same window.  The caller must hold the read lock.
+func (s *Server) deviceUptime(device Device, heartbeats []Heartbeat, from, to *time.Time) float32 {
+	interval := deviceTypeFor(device.Model).HeartbeatInterval()
+
+	if s.options.UptimeMode != UptimeModeFirstHeartbeat && s.options.UptimeMode != UptimeModeRegistration {
+		return calculateUptime(heartbeats, interval)
+	}
+	start, found := s.slotsStart(device, heartbeats)
+	if !found {
+		return 0
+	}
+
+	end := time.Now().UTC()
+	if from != nil && from.After(start) {
+		start = *from
+	}
+	if to != nil && to.Before(end) {
+		end = *to
+	}
+
+	observed, expected := countSlots(heartbeats, start, end, interval, s.options.UptimeTolerance)
+	if expected == 0 {
+		// nothing was due yet, so the device hasn't missed anything unless it has never been heard from
+		if len(heartbeats) == 0 {
+			return 0
+		}
+		return 100
+	}
+	return float32(observed) / float32(expected) * 100
+}
+
+// slotsStart returns when the slot based uptime modes start expecting heartbeats from the
+// device: its first heartbeat in the first_heartbeat mode, otherwise the same start as the
+// SLOs and alert rules so they all agree on the device's uptime.  found is false when the
+// device has never sent a heartbeat in the first_heartbeat mode.
+func (s *Server) slotsStart(device Device, heartbeats []Heartbeat) (start time.Time, found bool) {
+	if s.options.UptimeMode == UptimeModeFirstHeartbeat {
+		if len(heartbeats) == 0 {
+			return time.Time{}, false
+		}
+		// assuming that all heartbeats were received in chronological order
+		return heartbeats[0].SentAt, true
+	}
+	return s.heardSince(device, device.RegisteredAt), true
+}
+
+// heardSince moves the start of a window up to when the server could first have heard from
+// the device.  Heartbeats from before it was registered or before the server started were
+// never received, so the slots before then can't count as missed.
+func (s *Server) heardSince(device Device, from time.Time) time.Time {
+	if device.RegisteredAt.After(from) {
+		from = device.RegisteredAt
+	}
+	if s.startedAt.After(from) {
+		from = s.startedAt
+	}
+	return from
+}
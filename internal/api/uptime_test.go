@@ -0,0 +1,122 @@
+package api
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// the simulator starts every device at the same time and expects a heartbeat a minute
+var simulatorStart = time.Date(2025, 9, 29, 20, 0, 0, 0, time.UTC)
+
+// heartbeatsAt returns a heartbeat every interval from simulatorStart, offset by jitter,
+// for count intervals, leaving out the ones in missing
+func heartbeatsAt(count int, interval, jitter time.Duration, missing ...int) []Heartbeat {
+	var heartbeats []Heartbeat
+	for i := range count {
+		if slices.Contains(missing, i) {
+			continue
+		}
+		sentAt := simulatorStart.Add(time.Duration(i)*interval + jitter)
+		heartbeats = append(heartbeats, Heartbeat{SentAt: sentAt, ReportedAt: sentAt, ReceivedAt: sentAt})
+	}
+	return heartbeats
+}
+
+// missingHeartbeats returns n indexes spread between the first and last heartbeat of count,
+// so the span from the first to the last one stays the same
+func missingHeartbeats(count, n int) []int {
+	var missing []int
+	for i := range n {
+		missing = append(missing, 1+i*(count-2)/n)
+	}
+	return missing
+}
+
+// the simulator reports uptime to five decimal places
+func uptimeClose(got, want float32) bool {
+	return math.Abs(float64(got-want)) < 0.001
+}
+
+func TestCalculateUptime(t *testing.T) {
+	// the simulator's devices send up to 481 heartbeats a minute apart, a span of 480 minutes,
+	// the expected values are from results.txt
+	tests := []struct {
+		name       string
+		heartbeats []Heartbeat
+		interval   time.Duration
+		want       float32
+	}{
+		{name: "26-9a-66-01-33-83", heartbeats: heartbeatsAt(481, time.Minute, 0, missingHeartbeats(481, 35)...), want: 92.91667},
+		{name: "18-b8-87-e7-1f-06", heartbeats: heartbeatsAt(481, time.Minute, 0, missingHeartbeats(481, 7)...), want: 98.75},
+		{name: "38-4e-73-e0-33-59", heartbeats: heartbeatsAt(481, time.Minute, 0, missingHeartbeats(481, 2)...), want: 99.79167},
+		{name: "b4-45-52-a2-f1-3c", heartbeats: heartbeatsAt(481, time.Minute, 0, missingHeartbeats(481, 1)...), want: 100},
+		// n heartbeats only span n-1 intervals, which would be over 100% uncapped
+		{name: "capped at 100", heartbeats: heartbeatsAt(481, time.Minute, 0), want: 100},
+		{name: "single heartbeat", heartbeats: heartbeatsAt(1, time.Minute, 0), want: 100},
+		{name: "no heartbeats", heartbeats: nil, want: 0},
+		{name: "sensor interval", heartbeats: heartbeatsAt(97, 5*time.Minute, 0, missingHeartbeats(97, 25)...), interval: 5 * time.Minute, want: 75},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interval := time.Minute
+			if test.interval != 0 {
+				interval = test.interval
+			}
+			if got := calculateUptime(test.heartbeats, interval); !uptimeClose(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDeviceUptimeSlots(t *testing.T) {
+	device := Device{ID: "cam1", Model: DefaultDeviceModel, RegisteredAt: simulatorStart}
+	// registered 20 minutes before the device first sent anything
+	earlyDevice := Device{ID: "cam2", Model: DefaultDeviceModel, RegisteredAt: simulatorStart.Add(-20 * time.Minute)}
+	at := func(offset time.Duration) *time.Time {
+		t := simulatorStart.Add(offset)
+		return &t
+	}
+
+	tests := []struct {
+		name       string
+		mode       string
+		tolerance  time.Duration
+		startedAt  time.Time
+		device     Device
+		heartbeats []Heartbeat
+		to         *time.Time
+		want       float32
+	}{
+		// the same devices as the simulator, over the 480 minutes it expects heartbeats for
+		{name: "first heartbeat, simulator 26-9a-66-01-33-83", mode: UptimeModeFirstHeartbeat, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(480, time.Minute, 0, missingHeartbeats(480, 34)...), to: at(480 * time.Minute), want: 92.91667},
+		{name: "first heartbeat, simulator 18-b8-87-e7-1f-06", mode: UptimeModeFirstHeartbeat, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(480, time.Minute, 0, missingHeartbeats(480, 6)...), to: at(480 * time.Minute), want: 98.75},
+		{name: "first heartbeat, simulator b4-45-52-a2-f1-3c", mode: UptimeModeFirstHeartbeat, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(480, time.Minute, 0), to: at(480 * time.Minute), want: 100},
+		// the device went quiet halfway through, the span mode would still say 100
+		{name: "first heartbeat, gone quiet", mode: UptimeModeFirstHeartbeat, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(240, time.Minute, 0), to: at(480 * time.Minute), want: 50},
+		{name: "first heartbeat, no heartbeats", mode: UptimeModeFirstHeartbeat, device: device, to: at(480 * time.Minute), want: 0},
+		{name: "registration", mode: UptimeModeRegistration, tolerance: 30 * time.Second, device: earlyDevice, heartbeats: heartbeatsAt(460, time.Minute, 0), to: at(460 * time.Minute), want: 95.83333},
+		// the server restarted 20 minutes after the device was registered, it couldn't have heard
+		// the heartbeats from before then
+		{name: "registration, before the server started", mode: UptimeModeRegistration, tolerance: 30 * time.Second, startedAt: simulatorStart, device: earlyDevice, heartbeats: heartbeatsAt(460, time.Minute, 0), to: at(460 * time.Minute), want: 100},
+		{name: "registration, no heartbeats", mode: UptimeModeRegistration, device: device, to: at(60 * time.Minute), want: 0},
+		{name: "registration, nothing due yet", mode: UptimeModeRegistration, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(1, time.Minute, 0), to: at(0), want: 100},
+		// heartbeats half an interval late still land in the slot they were due in
+		{name: "jitter", mode: UptimeModeFirstHeartbeat, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(60, time.Minute, 25*time.Second), to: at(60 * time.Minute), want: 100},
+		// the heartbeat due 10s ago isn't missed yet with the tolerance, but is without it
+		{name: "within tolerance", mode: UptimeModeRegistration, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(10, time.Minute, 0), to: at(10*time.Minute + 10*time.Second), want: 100},
+		{name: "no tolerance", mode: UptimeModeRegistration, device: device, heartbeats: heartbeatsAt(10, time.Minute, 0), to: at(10*time.Minute + 10*time.Second), want: 90.90909},
+		// two heartbeats in every slot only count once each
+		{name: "capped at 100", mode: UptimeModeRegistration, tolerance: 30 * time.Second, device: device, heartbeats: heartbeatsAt(120, 30*time.Second, 0), to: at(60 * time.Minute), want: 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Server{options: Options{UptimeMode: test.mode, UptimeTolerance: test.tolerance}, startedAt: test.startedAt}
+			if got := s.deviceUptime(test.device, test.heartbeats, nil, test.to); !uptimeClose(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
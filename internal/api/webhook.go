@@ -0,0 +1,523 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fleetsy/pkg/api"
+)
+
+// events that can be sent to a webhook
+const (
+	EventDeviceOffline      = "device.offline"
+	EventDeviceRecovered    = "device.recovered"
+	EventUploadTimeExceeded = "upload_time.exceeded"
+)
+
+// WebhookEvents is the list of events a webhook can subscribe to
+var WebhookEvents = []string{EventDeviceOffline, EventDeviceRecovered, EventUploadTimeExceeded, EventAlertFiring, EventAlertResolved, EventAlertEscalated}
+
+// states a notification can be in
+const (
+	NotificationPending   = "pending"
+	NotificationDelivered = "delivered"
+	NotificationDead      = "dead" // ran out of attempts, kept so it can be redelivered
+)
+
+// ErrNotificationNotFound is returned when a notification id doesn't exist
+var ErrNotificationNotFound = errors.New("notification not found")
+
+const (
+	// how often the dispatcher looks for notifications that are due
+	webhookPollInterval = time.Second
+	// the delay before the first retry, doubled for every attempt after that
+	webhookBaseBackoff = time.Second
+	// retries never wait longer than this
+	webhookMaxBackoff = 5 * time.Minute
+	// how many deliveries can be in flight at once
+	webhookConcurrency = 4
+	// only the most recent notifications are kept
+	maxNotifications = 10000
+)
+
+// struct for the webhook registry
+type Webhook struct {
+	ID                  string   `json:"id"`
+	URL                 string   `json:"url"`
+	Events              []string `json:"events"`
+	UploadTimeThreshold string   `json:"upload_time_threshold,omitempty"`
+
+	secret              []byte
+	uploadTimeThreshold time.Duration
+}
+
+// struct for the incoming webhook PUT requests
+type WebhookPut struct {
+	URL                 string   `json:"url"`
+	Secret              string   `json:"secret"`
+	Events              []string `json:"events"`
+	UploadTimeThreshold string   `json:"upload_time_threshold"`
+}
+
+// response struct for the webhooks GET requests
+type WebhooksGet struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// struct for the notification store, one for every webhook or alert route an event was sent to
+type Notification struct {
+	ID            string          `json:"id"`
+	Webhook       string          `json:"webhook,omitempty"`
+	Route         string          `json:"route,omitempty"` // set instead of webhook for alert routes
+	Event         string          `json:"event"`
+	DeviceID      string          `json:"device_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"` // built once so every attempt sends the same body
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+
+	inFlight bool
+}
+
+// response struct for the notifications GET requests
+type NotificationsGet struct {
+	Notifications []Notification `json:"notifications"`
+}
+
+// the body posted to a webhook
+type webhookPayload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	DeviceID  string    `json:"device_id,omitempty"`
+	Data      any       `json:"data"`
+}
+
+// the data sent with upload_time.exceeded
+type uploadTimeExceeded struct {
+	SentAt     time.Time `json:"sent_at"`
+	UploadTime string    `json:"upload_time"`
+	Threshold  string    `json:"threshold"`
+}
+
+// (GET /webhooks)
+func (s *Server) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	response := WebhooksGet{Webhooks: []Webhook{}}
+	for _, webhook := range s.webhooks {
+		response.Webhooks = append(response.Webhooks, webhook)
+	}
+	slices.SortFunc(response.Webhooks, func(a, b Webhook) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	writeJSON(w, response)
+}
+
+// (PUT /webhooks/{webhook})
+func (s *Server) PutWebhooksWebhook(w http.ResponseWriter, r *http.Request, webhook api.WebhookPathParam) {
+	var newData WebhookPut
+	if err := json.NewDecoder(r.Body).Decode(&newData); err != nil {
+		recordError(r, err)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	hook, err := newWebhook(webhook, newData)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.webhookMutex.Lock()
+	s.webhooks[webhook] = hook
+	s.webhookMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (DELETE /webhooks/{webhook})
+func (s *Server) DeleteWebhooksWebhook(w http.ResponseWriter, r *http.Request, webhook api.WebhookPathParam) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	if _, found := s.webhooks[webhook]; !found {
+		writeError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+	delete(s.webhooks, webhook)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// (GET /notifications)
+func (s *Server) GetNotifications(w http.ResponseWriter, r *http.Request, params api.GetNotificationsParams) {
+	if params.Status != nil {
+		switch *params.Status {
+		case NotificationPending, NotificationDelivered, NotificationDead:
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("status must be %s, %s or %s", NotificationPending, NotificationDelivered, NotificationDead))
+			return
+		}
+	}
+
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	response := NotificationsGet{Notifications: []Notification{}}
+	for i := len(s.notificationOrder) - 1; i >= 0; i-- {
+		notification := s.notifications[s.notificationOrder[i]]
+		if params.Status != nil && notification.Status != *params.Status {
+			continue
+		}
+		if params.Webhook != nil && notification.Webhook != *params.Webhook {
+			continue
+		}
+		if params.Route != nil && notification.Route != *params.Route {
+			continue
+		}
+		response.Notifications = append(response.Notifications, *notification)
+	}
+
+	writeJSON(w, response)
+}
+
+// (POST /notifications/{notification}/redeliver)
+func (s *Server) PostNotificationsNotificationRedeliver(w http.ResponseWriter, r *http.Request, notification api.NotificationPathParam) {
+	if err := s.redeliver(notification); err != nil {
+		writeError(w, http.StatusNotFound, "Notification not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newWebhook validates a webhook PUT request
+func newWebhook(id string, newData WebhookPut) (Webhook, error) {
+	target, err := url.Parse(newData.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Webhook{}, errors.New("url must be an absolute http or https url")
+	}
+	if newData.Secret == "" {
+		return Webhook{}, errors.New("secret is required")
+	}
+	for _, event := range newData.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return Webhook{}, fmt.Errorf("events must be from %v", WebhookEvents)
+		}
+	}
+
+	webhook := Webhook{
+		ID:                  id,
+		URL:                 newData.URL,
+		Events:              newData.Events,
+		UploadTimeThreshold: newData.UploadTimeThreshold,
+		secret:              []byte(newData.Secret),
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	if newData.UploadTimeThreshold != "" {
+		threshold, err := time.ParseDuration(newData.UploadTimeThreshold)
+		if err != nil || threshold <= 0 {
+			return Webhook{}, errors.New("upload_time_threshold must be a positive duration. Eg: 5m")
+		}
+		webhook.uploadTimeThreshold = threshold
+	}
+	return webhook, nil
+}
+
+// wants reports whether the webhook is subscribed to an event
+func (h Webhook) wants(event string) bool {
+	return len(h.Events) == 0 || slices.Contains(h.Events, event)
+}
+
+// notify queues an event for every webhook subscribed to it.
+// match can narrow the webhooks further, eg to the ones whose threshold was passed.
+func (s *Server) notify(event, deviceId string, data func(Webhook) any, match func(Webhook) bool) {
+	now := time.Now().UTC()
+
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	for _, webhook := range s.webhooks {
+		if !webhook.wants(event) || (match != nil && !match(webhook)) {
+			continue
+		}
+
+		s.nextNotificationID++
+		id := strconv.Itoa(s.nextNotificationID)
+		payload, err := json.Marshal(webhookPayload{
+			ID:        id,
+			Event:     event,
+			CreatedAt: now,
+			DeviceID:  deviceId,
+			Data:      data(webhook),
+		})
+		if err != nil {
+			slog.Error("failed to encode notification", "event", event, "error", err)
+			continue
+		}
+
+		s.queueNotification(&Notification{
+			ID:       id,
+			Webhook:  webhook.ID,
+			Event:    event,
+			DeviceID: deviceId,
+			Payload:  payload,
+		}, now)
+	}
+}
+
+// queueNotification adds a notification to the delivery queue, giving it an id if it doesn't
+// have one yet, and wakes the dispatcher instead of waiting for the next poll.
+// The caller must hold the webhook lock.
+func (s *Server) queueNotification(notification *Notification, now time.Time) {
+	if notification.ID == "" {
+		s.nextNotificationID++
+		notification.ID = strconv.Itoa(s.nextNotificationID)
+	}
+	notification.Status = NotificationPending
+	notification.CreatedAt = now
+	notification.NextAttemptAt = &now
+	s.notifications[notification.ID] = notification
+	s.notificationOrder = append(s.notificationOrder, notification.ID)
+	s.trimNotifications()
+
+	select {
+	case s.webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// notifyPresence sends the presence changes webhooks care about
+func (s *Server) notifyPresence(deviceId string, event PresenceEvent) {
+	var name string
+	switch event.To {
+	case PresenceOffline:
+		name = EventDeviceOffline
+	case PresenceRecovered:
+		name = EventDeviceRecovered
+	default:
+		return
+	}
+	s.notify(name, deviceId, func(Webhook) any { return event }, nil)
+}
+
+// notifyUploadTime sends upload_time.exceeded to the webhooks whose threshold the upload passed
+func (s *Server) notifyUploadTime(deviceId string, stats DeviceStats) {
+	uploadTime := time.Duration(stats.UploadTime)
+	s.notify(EventUploadTimeExceeded, deviceId, func(webhook Webhook) any {
+		return uploadTimeExceeded{
+			SentAt:     stats.SentAt,
+			UploadTime: uploadTime.String(),
+			Threshold:  webhook.uploadTimeThreshold.String(),
+		}
+	}, func(webhook Webhook) bool {
+		return webhook.uploadTimeThreshold > 0 && uploadTime > webhook.uploadTimeThreshold
+	})
+}
+
+// trimNotifications drops the oldest notifications that aren't waiting to be sent once there
+// are too many.  The caller must hold the webhook lock.
+func (s *Server) trimNotifications() {
+	for excess := len(s.notificationOrder) - maxNotifications; excess > 0; excess-- {
+		removed := false
+		for i, id := range s.notificationOrder {
+			if s.notifications[id].Status != NotificationPending {
+				delete(s.notifications, id)
+				s.notificationOrder = slices.Delete(s.notificationOrder, i, i+1)
+				removed = true
+				break
+			}
+		}
+		// everything left is still pending, keep it rather than lose it
+		if !removed {
+			return
+		}
+	}
+}
+
+// redeliver gives a notification a fresh set of attempts
+func (s *Server) redeliver(id string) error {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	notification, found := s.notifications[id]
+	if !found {
+		return ErrNotificationNotFound
+	}
+	now := time.Now().UTC()
+	notification.Status = NotificationPending
+	notification.Attempts = 0
+	notification.NextAttemptAt = &now
+	notification.DeliveredAt = nil
+
+	select {
+	case s.webhookWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// RunWebhooks delivers notifications until ctx is cancelled, retrying failures with
+// exponential backoff until they run out of attempts
+func (s *Server) RunWebhooks(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+	// a pass can wait on a slow delivery to free up a slot, so it isn't stuck until the
+	// deliveries stop finishing
+	defer s.workerStarted(WorkerWebhooks, max(webhookPollInterval, s.options.WebhookTimeout))()
+	slots := make(chan struct{}, webhookConcurrency)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.webhookWake:
+		}
+
+		for _, id := range s.dueNotifications(time.Now().UTC()) {
+			slots <- struct{}{}
+			s.workerRan(WorkerWebhooks)
+			go func() {
+				defer func() { <-slots }()
+				s.deliver(ctx, id)
+			}()
+		}
+		s.workerRan(WorkerWebhooks)
+	}
+}
+
+// dueNotifications claims the pending notifications whose next attempt is due
+func (s *Server) dueNotifications(now time.Time) []string {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	var due []string
+	for _, id := range s.notificationOrder {
+		notification := s.notifications[id]
+		if notification.Status != NotificationPending || notification.inFlight || notification.NextAttemptAt.After(now) {
+			continue
+		}
+		notification.inFlight = true
+		due = append(due, id)
+	}
+	return due
+}
+
+// deliver makes a single attempt at posting a notification and records the result
+func (s *Server) deliver(ctx context.Context, id string) {
+	s.webhookMutex.Lock()
+	notification := s.notifications[id]
+	webhook, found := s.webhooks[notification.Webhook]
+	route, routeFound := s.routes[notification.Route]
+	isRoute := notification.Route != ""
+	payload := notification.Payload
+	event := notification.Event
+	s.webhookMutex.Unlock()
+
+	var err error
+	switch {
+	case isRoute && !routeFound:
+		err = errors.New("route was deleted")
+	case isRoute:
+		found = true
+		err = s.sendRoute(ctx, route, payload)
+	case !found:
+		err = errors.New("webhook was deleted")
+	default:
+		err = s.postWebhook(ctx, webhook, id, event, payload)
+	}
+
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	now := time.Now().UTC()
+	notification.inFlight = false
+	notification.Attempts++
+	if err == nil {
+		notification.Status = NotificationDelivered
+		notification.LastError = ""
+		notification.DeliveredAt = &now
+		notification.NextAttemptAt = nil
+		return
+	}
+
+	notification.LastError = err.Error()
+	if !found || notification.Attempts >= s.options.WebhookMaxAttempts {
+		// dead letter, it stays in the store until someone redelivers it
+		notification.Status = NotificationDead
+		notification.NextAttemptAt = nil
+		slog.Warn("notification is dead", "notification", id, "webhook", notification.Webhook, "route", notification.Route, "attempts", notification.Attempts, "error", err)
+		return
+	}
+	next := now.Add(webhookBackoff(notification.Attempts))
+	notification.NextAttemptAt = &next
+}
+
+// postWebhook sends the payload, signed with the webhook's secret
+func (s *Server) postWebhook(ctx context.Context, webhook Webhook, id, event string, payload []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	// the timestamp is signed along with the body so a captured request can't be replayed later
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Fleetsy-Event", event)
+	request.Header.Set("X-Fleetsy-Delivery", id)
+	request.Header.Set("X-Fleetsy-Timestamp", timestamp)
+	request.Header.Set("X-Fleetsy-Signature", "sha256="+signWebhook(webhook.secret, timestamp, payload))
+
+	response, err := s.webhookClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", response.Status)
+	}
+	return nil
+}
+
+// signWebhook returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+func signWebhook(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff is how long to wait after a failed attempt
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// devices are pinged once every heartbeat interval of their type, and a heartbeat is
+	// recorded for every pong that comes back.  This is how much longer than the interval to
+	// wait for the pong, or any other message, before deciding the device is gone.
+	wsPongGrace = 10 * time.Second
+	// how long a single write to the device may take
+	wsWriteWait = 10 * time.Second
+	// devices only send small json messages
+	wsMaxMessageSize = 4096
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// struct for the messages devices send over their websocket
+type WebSocketMessage struct {
+	Type   string `json:"type"` // "heartbeat" or "stats"
+	SentAt string `json:"sent_at"`
+	// stats messages carry the same fields as a stats POST for the device's type
+}
+
+// struct for the messages fleetsy sends back when a device message is rejected
+type WebSocketError struct {
+	Type    string `json:"type"` // always "error"
+	Message string `json:"message"`
+}
+
+// (GET /devices/{device_id}/ws)
+//
+// DeviceWebSocket holds a persistent connection open to a device.  The connection
+// itself is the liveness signal: fleetsy records a heartbeat when the device connects
+// and every time it answers a ping, unless the device already sent a heartbeat that
+// interval.  The device can also send heartbeat and stats messages over the same
+// connection instead of POSTing them.
+func (s *Server) DeviceWebSocket(w http.ResponseWriter, r *http.Request) {
+	deviceId := chi.URLParam(r, "device_id")
+
+	// validate the device exists in the db before upgrading
+	device, found := s.device(deviceId)
+	if !found {
+		writeNotFound(w)
+		return
+	}
+	// a sensor only heartbeats every 5 minutes, so it's pinged that often rather than every minute
+	pingInterval := deviceTypeFor(device.Model).HeartbeatInterval()
+	pongWait := pingInterval + wsPongGrace
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already sent an error response
+		return
+	}
+	defer conn.Close()
+
+	// connecting counts as a heartbeat, unless the device sent one of its own this interval
+	if err := s.addServerHeartbeat(r.Context(), deviceId, pingInterval); err != nil {
+		return
+	}
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		// a device that sends heartbeat messages as well would otherwise be counted twice
+		return s.addServerHeartbeat(r.Context(), deviceId, pingInterval)
+	})
+
+	// ping the device until the read loop below exits
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// WriteControl is safe to call alongside the writes in the read loop
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				slog.Info("websocket closed", "device_id", deviceId, "error", err)
+			}
+			return
+		}
+		// the device is still there even if its pongs are queued behind its messages
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		if err := s.handleWebSocketMessage(r.Context(), deviceId, data); err != nil {
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(WebSocketError{Type: "error", Message: err.Error()}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleWebSocketMessage decodes a single device message and stores it
+func (s *Server) handleWebSocketMessage(ctx context.Context, deviceId string, data []byte) error {
+	var message WebSocketMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return errors.New("invalid message")
+	}
+
+	// parse the timestamp
+	sentAt, err := time.Parse(time.RFC3339, message.SentAt)
+	if err != nil {
+		return errors.New("invalid sent_at")
+	}
+
+	switch message.Type {
+	case "heartbeat":
+		return s.addHeartbeat(ctx, deviceId, sentAt)
+	case "stats":
+		// the payload depends on the device type so let the type decode the whole message
+		stats, err := s.decodeStats(deviceId, data)
+		if err != nil {
+			return err
+		}
+		return s.addStats(ctx, deviceId, stats)
+	default:
+		return errors.New("unknown message type")
+	}
+}
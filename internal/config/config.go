@@ -0,0 +1,405 @@
+// Package config works out the server's settings from command line flags, FLEETSY_*
+// environment variables and an optional YAML or TOML config file.
+//
+// Every setting has one name used by all three: the flag is -webhook-timeout, the
+// environment variable is FLEETSY_WEBHOOK_TIMEOUT and the config file key is
+// webhook_timeout (or webhook-timeout).  A setting given in more than one place takes the
+// value from, highest first, the flags, the environment, the config file and then the
+// default.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	handlers "fleetsy/internal/api"
+)
+
+// EnvPrefix starts the name of every environment variable the config reads
+const EnvPrefix = "FLEETSY_"
+
+// StorageMemory is the only storage backend, everything is held in memory and lost on restart
+const StorageMemory = "memory"
+
+// where a setting's value came from
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Config is every setting the server reads at startup
+type Config struct {
+	// the config file the settings were read from, if there was one
+	File string
+
+	// where to listen
+	ListenAddr string // the HTTP api
+	UDPAddr    string // the UDP heartbeat listener, disabled when empty
+	GRPCAddr   string // the gRPC api, disabled when empty
+	UDPSecret  string // shared secret the UDP heartbeats are signed with
+
+	// data
+	DevicesFile    string        // the devices csv loaded at startup
+	StorageBackend string        // where the device store keeps its records, only memory for now
+	Retention      time.Duration // how long heartbeats, stats and samples are kept, forever when zero
+
+	// the HTTP server
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration // how long to spend finishing requests and flushing spans on the way out
+	MaxBodyBytes      int64
+
+	// device clock checks and anomaly detection
+	MaxClockSkew     time.Duration
+	CorrectClockSkew bool
+	AnomalyThreshold float64
+
+	// uptime and offline detection
+	UptimeMode            string
+	UptimeTolerance       time.Duration
+	PresenceCheckInterval time.Duration
+	PresenceGrace         time.Duration
+	OfflineAfterMissed    int
+
+	// webhooks and alerts
+	WebhookMaxAttempts int
+	WebhookTimeout     time.Duration
+	AlertEvalInterval  time.Duration
+
+	// the Prometheus endpoint
+	Metrics             bool
+	MetricsDeviceGauges bool
+	MetricsMaxDevices   int
+	MetricsDeviceGroups []string
+
+	// the websocket endpoint
+	WebSocket bool
+
+	// logging and tracing
+	LogLevel           string
+	LogHeartbeatSample int
+	TraceExporter      string
+	OTLPEndpoint       string
+	TraceSampleRatio   float64
+
+	// where each setting came from, by name
+	Sources map[string]string
+
+	flags *flag.FlagSet // bound to the fields above, for logging them
+}
+
+// Load reads the config from the command line args, the environment through lookupEnv and
+// the config file named by -config or FLEETSY_CONFIG, then validates it.  Every problem
+// with the settings is returned together so they can all be fixed at once.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := &Config{Sources: make(map[string]string)}
+	flags := config.flagSet()
+	config.flags = flags
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	// flags beat everything, so note which were given before the other sources are applied
+	flags.VisitAll(func(f *flag.Flag) {
+		config.Sources[f.Name] = SourceDefault
+	})
+	flags.Visit(func(f *flag.Flag) {
+		config.Sources[f.Name] = SourceFlag
+	})
+
+	var errs []error
+	if config.File == "" {
+		config.File, _ = lookupEnv(envName("config"))
+	}
+	if config.File != "" {
+		errs = append(errs, config.applyFile(flags))
+	}
+	errs = append(errs, config.applyEnv(flags, lookupEnv))
+	errs = append(errs, config.Validate())
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// flagSet binds a flag to every setting, with its default
+func (config *Config) flagSet() *flag.FlagSet {
+	flags := flag.NewFlagSet("fleetsy", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of fleetsy:\n\nEvery flag can also be set with a %s environment variable, eg %s, or in the\nconfig file, eg listen_addr.  Flags beat the environment, which beats the config file.\n\n", EnvPrefix+"<FLAG>", envName("listen-addr"))
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&config.File, "config", "", "YAML (.yaml, .yml) or TOML (.toml) config file")
+
+	// where to listen
+	flags.StringVar(&config.ListenAddr, "listen-addr", ":8080", "address for the HTTP api")
+	// optional lightweight heartbeat listener for devices on constrained links
+	flags.StringVar(&config.UDPAddr, "udp-addr", "", "address for the UDP heartbeat listener, eg :8081 (disabled when empty)")
+	// optional gRPC api for backend services
+	flags.StringVar(&config.GRPCAddr, "grpc-addr", "", "address for the gRPC api, eg :9090 (disabled when empty)")
+	// best set from the environment so it doesn't show up in the process list
+	flags.StringVar(&config.UDPSecret, "udp-secret", "", "shared secret the UDP heartbeats are signed with, required by -udp-addr")
+
+	// data
+	flags.StringVar(&config.DevicesFile, "devices-file", "devices.csv", "csv of the devices to accept data for")
+	flags.StringVar(&config.StorageBackend, "storage-backend", StorageMemory, "where the device store keeps its records, only memory is supported")
+	flags.DurationVar(&config.Retention, "retention", 0, "drop heartbeats, stats and metric samples received longer ago than this, eg 720h (kept forever when 0)")
+
+	// the HTTP server
+	flags.DurationVar(&config.ReadHeaderTimeout, "read-header-timeout", 10*time.Second, "how long a client has to send the request headers")
+	flags.DurationVar(&config.IdleTimeout, "idle-timeout", 2*time.Minute, "how long an idle keep-alive connection is kept open")
+	flags.DurationVar(&config.ShutdownTimeout, "shutdown-timeout", 5*time.Second, "how long to spend finishing requests in flight and flushing spans when the server is stopped")
+	// cap on request body size after decompression
+	flags.Int64Var(&config.MaxBodyBytes, "max-body-bytes", 10<<20, "maximum size of a request body after decompression")
+
+	// device clock checks
+	flags.DurationVar(&config.MaxClockSkew, "max-clock-skew", 0, "mark records whose sent_at is further than this from the server clock as skewed, eg 5m (disabled when 0)")
+	flags.BoolVar(&config.CorrectClockSkew, "correct-clock-skew", false, "use the server receive time instead of sent_at for skewed records")
+	// anomaly detection on upload time and heartbeat cadence
+	flags.Float64Var(&config.AnomalyThreshold, "anomaly-threshold", 3, "flag records at least this many standard deviations from the device's baseline (disabled when 0)")
+
+	// how uptime is worked out
+	flags.StringVar(&config.UptimeMode, "uptime-mode", handlers.UptimeModeSpan, "how uptime is worked out: span (first to last heartbeat), first_heartbeat or registration (expected heartbeats until now)")
+	flags.DurationVar(&config.UptimeTolerance, "uptime-tolerance", 30*time.Second, "how late a heartbeat can be before the first_heartbeat and registration uptime modes count it as missed")
+	// offline detection
+	flags.DurationVar(&config.PresenceCheckInterval, "presence-check-interval", 10*time.Second, "how often to check devices for missed heartbeats")
+	flags.DurationVar(&config.PresenceGrace, "presence-grace", 30*time.Second, "how overdue a heartbeat can be before the device is late")
+	flags.IntVar(&config.OfflineAfterMissed, "offline-after-missed", 3, "how many heartbeat intervals without a heartbeat before the device is offline")
+
+	// webhook delivery
+	flags.IntVar(&config.WebhookMaxAttempts, "webhook-max-attempts", 5, "how many times a webhook notification is sent before it is dead lettered")
+	flags.DurationVar(&config.WebhookTimeout, "webhook-timeout", 10*time.Second, "how long a single webhook delivery may take")
+	// alert rules
+	flags.DurationVar(&config.AlertEvalInterval, "alert-eval-interval", 30*time.Second, "how often alert rules are evaluated")
+
+	// per device gauges on /metrics, every device adds a series so they're off by default
+	flags.BoolVar(&config.Metrics, "metrics", true, "serve Prometheus metrics on /metrics")
+	flags.BoolVar(&config.MetricsDeviceGauges, "metrics-device-gauges", false, "export uptime, upload time and heartbeat age gauges for every device on /metrics")
+	flags.IntVar(&config.MetricsMaxDevices, "metrics-max-devices", 1000, "at most this many devices get gauges on /metrics (no limit when 0)")
+	flags.Var((*listValue)(&config.MetricsDeviceGroups), "metrics-device-groups", "comma separated groups whose devices get gauges on /metrics (every group when empty)")
+
+	// devices can hold a websocket open instead of posting heartbeats
+	flags.BoolVar(&config.WebSocket, "websocket", true, "accept device websockets on /api/v1/devices/{device_id}/ws")
+
+	// logging
+	flags.StringVar(&config.LogLevel, "log-level", "info", "lowest level to log: debug, info, warn or error")
+	flags.IntVar(&config.LogHeartbeatSample, "log-heartbeat-sample", 1, "only log one in this many successful heartbeat requests, failures are always logged")
+	// tracing
+	flags.StringVar(&config.TraceExporter, "trace-exporter", handlers.TraceExporterNone, "where to export OpenTelemetry spans: none, stdout or otlp")
+	flags.StringVar(&config.OTLPEndpoint, "otlp-endpoint", "", "url of the OTLP/HTTP collector, eg http://localhost:4318 (the OTEL_EXPORTER_OTLP_* variables when empty)")
+	flags.Float64Var(&config.TraceSampleRatio, "trace-sample-ratio", 1, "fraction of new traces to keep, traces started by a caller follow its traceparent")
+
+	return flags
+}
+
+// applyFile sets everything in the config file that wasn't given as a flag
+func (config *Config) applyFile(flags *flag.FlagSet) error {
+	data, err := os.ReadFile(config.File)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(config.File)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("config file %s must end in .yaml, .yml or .toml", config.File)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", config.File, err)
+	}
+
+	var errs []error
+	for key, value := range values {
+		name := strings.ReplaceAll(key, "_", "-")
+		if name == "config" || flags.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", config.File, key))
+			continue
+		}
+		if config.Sources[name] == SourceFlag {
+			continue
+		}
+		text, err := fileValue(value)
+		if err == nil {
+			err = set(flags, name, text)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", config.File, key, err))
+			continue
+		}
+		config.Sources[name] = SourceFile
+	}
+	return sortedJoin(errs)
+}
+
+// fileValue turns a value from the config file into the text its flag parses.  Lists are
+// joined with commas, the same as they're written on the command line.
+func fileValue(value any) (string, error) {
+	switch value := value.(type) {
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			text, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = text
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", errors.New("must be a single value or a list, not a table")
+	case nil:
+		return "", nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// applyEnv sets everything from a FLEETSY_* variable that wasn't given as a flag
+func (config *Config) applyEnv(flags *flag.FlagSet, lookupEnv func(string) (string, bool)) error {
+	var errs []error
+	flags.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || config.Sources[f.Name] == SourceFlag {
+			return
+		}
+		value, found := lookupEnv(envName(f.Name))
+		if !found {
+			return
+		}
+		if err := set(flags, f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", envName(f.Name), err))
+			return
+		}
+		config.Sources[f.Name] = SourceEnv
+	})
+	return errors.Join(errs...)
+}
+
+// set parses a value into a setting.  A bad value leaves the setting as it was, the flag
+// package zeroes some of them, so validation doesn't go on to complain about the zero too.
+func set(flags *flag.FlagSet, name, value string) error {
+	previous := flags.Lookup(name).Value.String()
+	if err := flags.Set(name, value); err != nil {
+		flags.Set(name, previous)
+		return fmt.Errorf("invalid value %q: %w", value, err)
+	}
+	return nil
+}
+
+// envName is the environment variable for a setting, eg FLEETSY_LISTEN_ADDR for listen-addr
+func envName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Validate checks every setting, returning all the problems it finds
+func (config *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(config.ListenAddr != "", "listen-addr can't be empty")
+	check(config.UDPAddr == "" || config.UDPSecret != "", "udp-secret (or %s) must be set to use the UDP heartbeat listener", envName("udp-secret"))
+	check(config.DevicesFile != "", "devices-file can't be empty")
+	check(config.StorageBackend == StorageMemory, "storage-backend %q isn't supported, the only backend is %s", config.StorageBackend, StorageMemory)
+	check(config.Retention >= 0, "retention can't be negative")
+
+	check(config.ReadHeaderTimeout >= 0, "read-header-timeout can't be negative")
+	check(config.IdleTimeout >= 0, "idle-timeout can't be negative")
+	check(config.ShutdownTimeout > 0, "shutdown-timeout must be positive")
+	check(config.MaxBodyBytes > 0, "max-body-bytes must be positive")
+
+	check(config.MaxClockSkew >= 0, "max-clock-skew can't be negative")
+	check(config.AnomalyThreshold >= 0, "anomaly-threshold can't be negative")
+	if err := handlers.ValidateUptimeMode(config.UptimeMode); err != nil {
+		errs = append(errs, err)
+	}
+	check(config.UptimeTolerance >= 0, "uptime-tolerance can't be negative")
+	check(config.PresenceCheckInterval > 0, "presence-check-interval must be positive")
+	check(config.PresenceGrace >= 0, "presence-grace can't be negative")
+	check(config.OfflineAfterMissed >= 1, "offline-after-missed must be at least 1")
+
+	check(config.WebhookMaxAttempts >= 1, "webhook-max-attempts must be at least 1")
+	check(config.WebhookTimeout > 0, "webhook-timeout must be positive")
+	check(config.AlertEvalInterval > 0, "alert-eval-interval must be positive")
+	check(config.MetricsMaxDevices >= 0, "metrics-max-devices can't be negative")
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("log-level: %w", err))
+	}
+	check(config.LogHeartbeatSample >= 1, "log-heartbeat-sample must be at least 1")
+	check(slices.Contains([]string{handlers.TraceExporterNone, handlers.TraceExporterStdout, handlers.TraceExporterOTLP}, config.TraceExporter),
+		"trace-exporter must be %s, %s or %s", handlers.TraceExporterNone, handlers.TraceExporterStdout, handlers.TraceExporterOTLP)
+	check(config.TraceSampleRatio >= 0 && config.TraceSampleRatio <= 1, "trace-sample-ratio must be between 0 and 1")
+
+	return errors.Join(errs...)
+}
+
+// Level is the parsed log level, Validate has already checked it
+func (config *Config) Level() slog.Level {
+	var level slog.Level
+	level.UnmarshalText([]byte(config.LogLevel))
+	return level
+}
+
+// LogValue lets the config be logged at startup, leaving out the secret
+func (config *Config) LogValue() slog.Value {
+	var attrs []slog.Attr
+	config.flags.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if f.Name == "udp-secret" && value != "" {
+			value = "<hidden>"
+		}
+		attrs = append(attrs, slog.Group(f.Name, "value", value, "source", config.Sources[f.Name]))
+	})
+	return slog.GroupValue(attrs...)
+}
+
+// sortedJoin joins errors in a stable order, map iteration would shuffle them between runs
+func sortedJoin(errs []error) error {
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return errors.Join(errs...)
+}
+
+// listValue is a comma separated flag, dropping empty entries
+type listValue []string
+
+// String joins the list back up with commas
+func (list *listValue) String() string {
+	return strings.Join(*list, ",")
+}
+
+// Set replaces the list, so a later source overrides it rather than adding to it
+func (list *listValue) Set(value string) error {
+	*list = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*list = append(*list, item)
+		}
+	}
+	return nil
+}
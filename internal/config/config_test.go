@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes a config file to a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// env looks variables up in a map instead of the process environment
+func env(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, found := values[name]
+		return value, found
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "fleetsy.yaml", `
+listen_addr: ":7000"
+webhook-timeout: 20s
+retention: 720h
+idle_timeout: 1m
+metrics_device_groups: [site-a, site-b]
+`)
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		// the listen address, webhook timeout, retention and idle timeout that should win
+		listenAddr     string
+		webhookTimeout time.Duration
+		retention      time.Duration
+		idleTimeout    time.Duration
+		sources        map[string]string
+	}{
+		{
+			name: "defaults", listenAddr: ":8080", webhookTimeout: 10 * time.Second, idleTimeout: 2 * time.Minute,
+			sources: map[string]string{"listen-addr": SourceDefault, "webhook-timeout": SourceDefault, "retention": SourceDefault},
+		},
+		{
+			name: "file beats defaults", args: []string{"-config", file},
+			listenAddr: ":7000", webhookTimeout: 20 * time.Second, retention: 720 * time.Hour, idleTimeout: time.Minute,
+			sources: map[string]string{"listen-addr": SourceFile, "webhook-timeout": SourceFile, "retention": SourceFile, "read-header-timeout": SourceDefault},
+		},
+		{
+			name: "env beats file", env: map[string]string{"FLEETSY_CONFIG": file, "FLEETSY_LISTEN_ADDR": ":7100", "FLEETSY_WEBHOOK_TIMEOUT": "30s"},
+			listenAddr: ":7100", webhookTimeout: 30 * time.Second, retention: 720 * time.Hour, idleTimeout: time.Minute,
+			sources: map[string]string{"listen-addr": SourceEnv, "webhook-timeout": SourceEnv, "retention": SourceFile},
+		},
+		{
+			name: "flag beats env and file", args: []string{"-config", file, "-listen-addr", ":7200", "-retention", "24h"},
+			env: map[string]string{"FLEETSY_LISTEN_ADDR": ":7100", "FLEETSY_WEBHOOK_TIMEOUT": "30s", "FLEETSY_RETENTION": "48h"}, listenAddr: ":7200", webhookTimeout: 30 * time.Second, retention: 24 * time.Hour, idleTimeout: time.Minute,
+			sources: map[string]string{"listen-addr": SourceFlag, "webhook-timeout": SourceEnv, "retention": SourceFlag, "idle-timeout": SourceFile},
+		},
+		// the -config flag beats FLEETSY_CONFIG too
+		{
+			name: "config flag beats env", args: []string{"-config", file}, env: map[string]string{"FLEETSY_CONFIG": "missing.yaml"},
+			listenAddr: ":7000", webhookTimeout: 20 * time.Second, retention: 720 * time.Hour, idleTimeout: time.Minute,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := Load(test.args, env(test.env))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.ListenAddr != test.listenAddr || config.WebhookTimeout != test.webhookTimeout || config.Retention != test.retention || config.IdleTimeout != test.idleTimeout {
+				t.Errorf("got listen-addr %s, webhook-timeout %s, retention %s, idle-timeout %s, want %s, %s, %s, %s",
+					config.ListenAddr, config.WebhookTimeout, config.Retention, config.IdleTimeout,
+					test.listenAddr, test.webhookTimeout, test.retention, test.idleTimeout)
+			}
+			for name, want := range test.sources {
+				if got := config.Sources[name]; got != want {
+					t.Errorf("%s: got source %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadLists(t *testing.T) {
+	// a list in the file is replaced by the environment rather than added to
+	file := writeFile(t, "fleetsy.toml", `metrics_device_groups = ["site-a", "site-b"]`)
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{env: map[string]string{"FLEETSY_CONFIG": file}, want: "site-a,site-b"},
+		{env: map[string]string{"FLEETSY_CONFIG": file, "FLEETSY_METRICS_DEVICE_GROUPS": " site-c,, site-d "}, want: "site-c,site-d"},
+	}
+	for _, test := range tests {
+		config, err := Load(nil, env(test.env))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(config.MetricsDeviceGroups, ","); got != test.want {
+			t.Errorf("got groups %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		file string
+		// every one of these should be reported
+		want []string
+	}{
+		{name: "udp without a secret", args: []string{"-udp-addr", ":8081"}, want: []string{"udp-secret (or FLEETSY_UDP_SECRET) must be set"}},
+		{name: "negative durations", args: []string{"-max-clock-skew", "-1s", "-retention", "-1h"}, want: []string{"max-clock-skew can't be negative", "retention can't be negative"}},
+		{name: "zero shutdown timeout", env: map[string]string{"FLEETSY_SHUTDOWN_TIMEOUT": "0s"}, want: []string{"shutdown-timeout must be positive"}},
+		{name: "unknown storage", args: []string{"-storage-backend", "postgres"}, want: []string{`storage-backend "postgres" isn't supported`}},
+		{name: "bad uptime mode", args: []string{"-uptime-mode", "always"}, want: []string{"uptime mode must be one of"}},
+		{name: "bad log level", args: []string{"-log-level", "loud"}, want: []string{"log-level:"}},
+		{name: "bad trace exporter", args: []string{"-trace-exporter", "jaeger"}, want: []string{"trace-exporter must be none, stdout or otlp"}},
+		{name: "sample ratio out of range", args: []string{"-trace-sample-ratio", "1.5"}, want: []string{"trace-sample-ratio must be between 0 and 1"}},
+		// a bad value keeps the default, so it isn't reported a second time as a zero
+		{name: "bad env value", env: map[string]string{"FLEETSY_WEBHOOK_TIMEOUT": "abc"}, want: []string{`FLEETSY_WEBHOOK_TIMEOUT: invalid value "abc"`}},
+		{name: "unknown file key", file: "listen_addr: \":7000\"\nwebhook_timout: 20s\n", want: []string{`unknown setting "webhook_timout"`}},
+		{name: "config isn't a file key", file: "config: other.yaml\n", want: []string{`unknown setting "config"`}},
+		{name: "table in the file", file: "listen_addr:\n  host: localhost\n", want: []string{"listen_addr: must be a single value or a list, not a table"}},
+		{name: "bad file value", file: "offline_after_missed: lots\n", want: []string{`offline_after_missed: invalid value "lots"`}},
+		{name: "missing file", args: []string{"-config", "missing.yaml"}, want: []string{"failed to read config file"}},
+		{name: "unexpected arguments", args: []string{"serve"}, want: []string{"unexpected arguments: serve"}},
+		// all the problems come back together
+		{
+			name: "several problems",
+			args: []string{"-offline-after-missed", "0", "-storage-backend", "postgres"},
+			env:  map[string]string{"FLEETSY_WEBHOOK_TIMEOUT": "abc"},
+			want: []string{"FLEETSY_WEBHOOK_TIMEOUT: invalid value", "storage-backend", "offline-after-missed must be at least 1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := test.args
+			if test.file != "" {
+				args = append(args, "-config", writeFile(t, "fleetsy.yaml", test.file))
+			}
+			_, err := Load(args, env(test.env))
+			if err == nil {
+				t.Fatalf("got no error, want %q", test.want)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got error %q, want it to include %q", err, want)
+				}
+			}
+			if lines := strings.Count(err.Error(), "\n") + 1; lines != len(test.want) {
+				t.Errorf("got %d errors %q, want %d", lines, err, len(test.want))
+			}
+		})
+	}
+}
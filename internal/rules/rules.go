@@ -0,0 +1,245 @@
+// Package rules parses the expressions used by alert rules.
+//
+// An expression compares a function of a device's recent history with a threshold, and can
+// require the comparison to hold for a while before it counts:
+//
+//	uptime(24h) < 95 for 30m
+//	p95(upload_time, 1h) > 5m
+//	failure_rate(6h) >= 10
+//
+// Functions take a window, the stretch of history ending now that they look at.  Upload time
+// functions also name the series first, which is always upload_time for now, and are compared
+// with durations.  Everything else is compared with plain numbers.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// functions an expression can use
+const (
+	FuncUptime      = "uptime"       // percentage of expected heartbeats that arrived
+	FuncHeartbeats  = "heartbeats"   // number of heartbeats
+	FuncUploads     = "uploads"      // number of uploads
+	FuncFailureRate = "failure_rate" // percentage of uploads that failed
+	FuncAvg         = "avg"
+	FuncMin         = "min"
+	FuncMax         = "max"
+	FuncP50         = "p50"
+	FuncP90         = "p90"
+	FuncP95         = "p95"
+	FuncP99         = "p99"
+)
+
+// SeriesUploadTime is the only series the aggregate functions work on
+const SeriesUploadTime = "upload_time"
+
+// functions that take just a window
+var windowFuncs = []string{FuncUptime, FuncHeartbeats, FuncUploads, FuncFailureRate}
+
+// functions that take a series and a window
+var seriesFuncs = []string{FuncAvg, FuncMin, FuncMax, FuncP50, FuncP90, FuncP95, FuncP99}
+
+// MaxWindow is the most history a function can look at.  Uptime keeps a slot for every
+// heartbeat due in the window on every evaluation, so it's capped the same as SLO windows.
+const MaxWindow = 400 * 24 * time.Hour
+
+// comparison operators, longest first so <= isn't read as <
+var operators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+// Expr is a parsed alert expression
+type Expr struct {
+	Func      string
+	Series    string        // empty unless Func is an aggregate
+	Window    time.Duration // how much history the function looks at
+	Op        string
+	Threshold float64       // durations are in nanoseconds
+	For       time.Duration // how long the comparison must hold, zero fires straight away
+}
+
+// IsDuration reports whether the function returns a duration in nanoseconds
+func (e Expr) IsDuration() bool {
+	return e.Series == SeriesUploadTime
+}
+
+// Compare applies the expression's operator to a value
+func (e Expr) Compare(value float64) bool {
+	switch e.Op {
+	case "<":
+		return value < e.Threshold
+	case "<=":
+		return value <= e.Threshold
+	case ">":
+		return value > e.Threshold
+	case ">=":
+		return value >= e.Threshold
+	case "==":
+		return value == e.Threshold
+	case "!=":
+		return value != e.Threshold
+	}
+	return false
+}
+
+// Parse parses an expression like "uptime(24h) < 95 for 30m"
+func Parse(input string) (Expr, error) {
+	p := parser{tokens: tokenize(input)}
+	expr, err := p.expr()
+	if err != nil {
+		return Expr{}, fmt.Errorf("invalid expression %q: %w", input, err)
+	}
+	return expr, nil
+}
+
+// parser walks the tokens of an expression
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// next returns the next token, or "" at the end
+func (p *parser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+// expect consumes a token that must be exactly want
+func (p *parser) expect(want string) error {
+	if got := p.next(); got != want {
+		return fmt.Errorf("expected %q, got %q", want, got)
+	}
+	return nil
+}
+
+// expr := func "(" [series ","] window ")" op threshold ["for" duration]
+func (p *parser) expr() (Expr, error) {
+	var expr Expr
+	expr.Func = p.next()
+	isWindowFunc := slices.Contains(windowFuncs, expr.Func)
+	isSeriesFunc := slices.Contains(seriesFuncs, expr.Func)
+	if !isWindowFunc && !isSeriesFunc {
+		return Expr{}, fmt.Errorf("unknown function %q", expr.Func)
+	}
+
+	if err := p.expect("("); err != nil {
+		return Expr{}, err
+	}
+	if isSeriesFunc {
+		expr.Series = p.next()
+		if expr.Series != SeriesUploadTime {
+			return Expr{}, fmt.Errorf("%s only works on %s", expr.Func, SeriesUploadTime)
+		}
+		if err := p.expect(","); err != nil {
+			return Expr{}, err
+		}
+	}
+	window, err := ParseDuration(p.next())
+	if err != nil {
+		return Expr{}, fmt.Errorf("the window must be a positive duration, eg 1h: %w", err)
+	}
+	if window <= 0 {
+		return Expr{}, errors.New("the window must be a positive duration, eg 1h")
+	}
+	if window > MaxWindow {
+		return Expr{}, errors.New("the window can't be longer than 400d")
+	}
+	expr.Window = window
+	if err := p.expect(")"); err != nil {
+		return Expr{}, err
+	}
+
+	expr.Op = p.next()
+	if !slices.Contains(operators, expr.Op) {
+		return Expr{}, fmt.Errorf("expected a comparison, got %q", expr.Op)
+	}
+
+	threshold := p.next()
+	if expr.IsDuration() {
+		d, err := ParseDuration(threshold)
+		if err != nil {
+			return Expr{}, fmt.Errorf("%s is compared with a duration, eg 5m", expr.Series)
+		}
+		expr.Threshold = float64(d)
+	} else {
+		expr.Threshold, err = strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return Expr{}, fmt.Errorf("%s is compared with a number, got %q", expr.Func, threshold)
+		}
+	}
+
+	switch p.next() {
+	case "":
+		return expr, nil
+	case "for":
+		expr.For, err = ParseDuration(p.next())
+		if err != nil || expr.For < 0 {
+			return Expr{}, errors.New("for must be a duration, eg 30m")
+		}
+	default:
+		return Expr{}, fmt.Errorf("unexpected %q", p.tokens[p.pos-1])
+	}
+	if token := p.next(); token != "" {
+		return Expr{}, fmt.Errorf("unexpected %q", token)
+	}
+	return expr, nil
+}
+
+// tokenize splits an expression into words, numbers, durations, operators and punctuation
+func tokenize(input string) []string {
+	var tokens []string
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, string(r))
+			i++
+		case strings.ContainsRune("<>=!", r):
+			// one or two character operators
+			j := i + 1
+			if j < len(runes) && runes[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			// words, numbers and durations run until the next separator
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("(),<>=!", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens
+}
+
+// ParseDuration parses a Go duration, also allowing whole days like 30d
+func ParseDuration(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.ParseInt(days, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		// the multiplication would wrap around rather than fail
+		if count > math.MaxInt64/int64(24*time.Hour) || count < math.MinInt64/int64(24*time.Hour) {
+			return 0, fmt.Errorf("duration %q is out of range", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
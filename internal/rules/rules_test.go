@@ -0,0 +1,189 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Expr
+	}{
+		{input: "uptime(24h) < 95 for 30m", want: Expr{Func: FuncUptime, Window: 24 * time.Hour, Op: "<", Threshold: 95, For: 30 * time.Minute}},
+		{input: "p95(upload_time, 1h) > 5m", want: Expr{Func: FuncP95, Series: SeriesUploadTime, Window: time.Hour, Op: ">", Threshold: float64(5 * time.Minute)}},
+		{input: "failure_rate(6h) >= 10", want: Expr{Func: FuncFailureRate, Window: 6 * time.Hour, Op: ">=", Threshold: 10}},
+		{input: "heartbeats(1h) == 0", want: Expr{Func: FuncHeartbeats, Window: time.Hour, Op: "==", Threshold: 0}},
+		{input: "uploads(30d) != 0.5 for 0s", want: Expr{Func: FuncUploads, Window: 30 * 24 * time.Hour, Op: "!=", Threshold: 0.5}},
+		{input: "avg(upload_time, 400d) <= 1m30s", want: Expr{Func: FuncAvg, Series: SeriesUploadTime, Window: MaxWindow, Op: "<=", Threshold: float64(90 * time.Second)}},
+		// spaces are optional between tokens
+		{input: "uptime(24h)<95 for 30m", want: Expr{Func: FuncUptime, Window: 24 * time.Hour, Op: "<", Threshold: 95, For: 30 * time.Minute}},
+		{input: "max(upload_time,1h)>=-1s", want: Expr{Func: FuncMax, Series: SeriesUploadTime, Window: time.Hour, Op: ">=", Threshold: float64(-time.Second)}},
+		{input: "  uptime( 1h )  >  -5  ", want: Expr{Func: FuncUptime, Window: time.Hour, Op: ">", Threshold: -5}},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := Parse(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseOperators(t *testing.T) {
+	// two character operators are read whole, so <= is never < followed by =
+	tests := []struct {
+		input string
+		op    string
+		err   string
+	}{
+		{input: "uptime(1h)<=95", op: "<="},
+		{input: "uptime(1h)>=95", op: ">="},
+		{input: "uptime(1h)==95", op: "=="},
+		{input: "uptime(1h)!=95", op: "!="},
+		{input: "uptime(1h)<95", op: "<"},
+		{input: "uptime(1h)>95", op: ">"},
+		{input: "uptime(1h) < = 95", err: `uptime is compared with a number, got "="`},
+		{input: "uptime(1h) =< 95", err: `expected a comparison, got "="`},
+		{input: "uptime(1h) >== 95", err: `uptime is compared with a number, got "="`},
+		{input: "uptime(1h) = 95", err: `expected a comparison, got "="`},
+		{input: "uptime(1h) ! 95", err: `expected a comparison, got "!"`},
+		{input: "uptime(1h) <> 95", err: `uptime is compared with a number, got ">"`},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := Parse(test.input)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Op != test.op {
+				t.Errorf("got %q, want %q", got.Op, test.op)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{input: "", err: `unknown function ""`},
+		{input: "latency(1h) > 5", err: `unknown function "latency"`},
+		{input: "uptime", err: `expected "(", got ""`},
+		{input: "uptime 1h < 95", err: `expected "(", got "1h"`},
+		{input: "uptime(1h < 95", err: `expected ")", got "<"`},
+		{input: "uptime() < 95", err: "the window must be a positive duration"},
+		{input: "uptime(0s) < 95", err: "the window must be a positive duration"},
+		{input: "uptime(-1h) < 95", err: "the window must be a positive duration"},
+		{input: "uptime(soon) < 95", err: "the window must be a positive duration"},
+		{input: "uptime(401d) < 95", err: "the window can't be longer than 400d"},
+		{input: "uptime(1h)", err: `expected a comparison, got ""`},
+		{input: "uptime(1h) <", err: `uptime is compared with a number, got ""`},
+		{input: "uptime(1h) < 5m", err: `uptime is compared with a number, got "5m"`},
+		{input: "p95(upload_time, 1h) > 5", err: "upload_time is compared with a duration"},
+		{input: "p95(1h) > 5m", err: "p95 only works on upload_time"},
+		{input: "p95(heartbeats, 1h) > 5m", err: "p95 only works on upload_time"},
+		{input: "p95(upload_time 1h) > 5m", err: `expected ",", got "1h"`},
+		{input: "uptime(upload_time, 1h) < 95", err: "the window must be a positive duration"},
+		{input: "uptime(1h) < 95 for", err: "for must be a duration"},
+		{input: "uptime(1h) < 95 for -5m", err: "for must be a duration"},
+		{input: "uptime(1h) < 95 for 5m 10m", err: `unexpected "10m"`},
+		{input: "uptime(1h) < 95 and uploads(1h) > 0", err: `unexpected "and"`},
+		{input: "uptime(1h) < 95)", err: `unexpected ")"`},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := Parse(test.input)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("got error %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	// the most whole days that fit in a time.Duration
+	const maxDays = 106751
+
+	tests := []struct {
+		input string
+		want  time.Duration
+		err   string
+	}{
+		{input: "30d", want: 30 * 24 * time.Hour},
+		{input: "0d", want: 0},
+		{input: "-2d", want: -48 * time.Hour},
+		{input: "1h30m", want: 90 * time.Minute},
+		{input: "106751d", want: maxDays * 24 * time.Hour},
+		{input: "-106751d", want: -maxDays * 24 * time.Hour},
+		// one more day would wrap around to a negative duration
+		{input: "106752d", err: "out of range"},
+		{input: "-106752d", err: "out of range"},
+		{input: "9223372036854775807d", err: "out of range"},
+		{input: "9223372036854775808d", err: "invalid duration"},
+		{input: "2562048h", err: "invalid duration"},
+		{input: "1.5d", err: "invalid duration"},
+		{input: "d", err: "invalid duration"},
+		{input: "", err: "invalid duration"},
+		{input: "5", err: "missing unit"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseDuration(test.input)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got %s, error %v, want %q", got, err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	// an overflowing window is reported as out of range, not as a negative window
+	if _, err := Parse("uptime(106752d) < 95"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("got error %v, want out of range", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		op   string
+		want []bool // for values below, equal to and above the threshold
+	}{
+		{op: "<", want: []bool{true, false, false}},
+		{op: "<=", want: []bool{true, true, false}},
+		{op: ">", want: []bool{false, false, true}},
+		{op: ">=", want: []bool{false, true, true}},
+		{op: "==", want: []bool{false, true, false}},
+		{op: "!=", want: []bool{true, false, true}},
+		{op: "=<", want: []bool{false, false, false}},
+	}
+	for _, test := range tests {
+		t.Run(test.op, func(t *testing.T) {
+			expr := Expr{Op: test.op, Threshold: 95}
+			for i, value := range []float64{94.9, 95, 95.1} {
+				if got := expr.Compare(value); got != test.want[i] {
+					t.Errorf("%v %s 95: got %v, want %v", value, test.op, got, test.want[i])
+				}
+			}
+		})
+	}
+}
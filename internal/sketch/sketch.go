@@ -0,0 +1,203 @@
+// Package sketch provides a mergeable quantile sketch.
+//
+// The sketch follows the DDSketch approach: values are counted in logarithmically
+// sized bins so any quantile can be answered with a bounded relative error, no matter
+// how many values were added.  Two sketches with the same accuracy can be merged
+// exactly, which makes it cheap to keep one per device and combine them for a group.
+package sketch
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrIncompatible is returned when merging sketches created with different accuracies
+var ErrIncompatible = errors.New("sketch: can't merge sketches with different accuracies")
+
+// Sketch summarizes a stream of non-negative values
+type Sketch struct {
+	relativeAccuracy float64
+	gamma            float64
+	logGamma         float64
+
+	bins      map[int]uint64 // bin index -> number of values in the bin
+	zeroCount uint64         // values too small to have a bin
+
+	count uint64
+	min   float64
+	max   float64
+	sum   float64
+	sumSq float64
+}
+
+// the smallest value that gets its own bin, anything below counts as zero
+const minIndexableValue = 1e-9
+
+// New creates an empty sketch whose quantiles are within relativeAccuracy of the
+// true value, eg 0.01 for 1%
+func New(relativeAccuracy float64) *Sketch {
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &Sketch{
+		relativeAccuracy: relativeAccuracy,
+		gamma:            gamma,
+		logGamma:         math.Log(gamma),
+		bins:             make(map[int]uint64),
+	}
+}
+
+// Add records a value.  Negative values are treated as zero.
+func (s *Sketch) Add(value float64) {
+	value = max(value, 0)
+	if value < minIndexableValue {
+		s.zeroCount++
+	} else {
+		s.bins[s.index(value)]++
+	}
+
+	if s.count == 0 {
+		s.min, s.max = value, value
+	}
+	s.min = min(s.min, value)
+	s.max = max(s.max, value)
+	s.count++
+	s.sum += value
+	s.sumSq += value * value
+}
+
+// Merge adds every value recorded in other to s
+func (s *Sketch) Merge(other *Sketch) error {
+	if other.count == 0 {
+		return nil
+	}
+	if other.relativeAccuracy != s.relativeAccuracy {
+		return ErrIncompatible
+	}
+
+	for index, count := range other.bins {
+		s.bins[index] += count
+	}
+	s.zeroCount += other.zeroCount
+
+	if s.count == 0 {
+		s.min, s.max = other.min, other.max
+	}
+	s.min = min(s.min, other.min)
+	s.max = max(s.max, other.max)
+	s.count += other.count
+	s.sum += other.sum
+	s.sumSq += other.sumSq
+	return nil
+}
+
+// Copy returns an independent copy of the sketch
+func (s *Sketch) Copy() *Sketch {
+	c := *s
+	c.bins = make(map[int]uint64, len(s.bins))
+	for index, count := range s.bins {
+		c.bins[index] = count
+	}
+	return &c
+}
+
+// Count returns the number of values recorded
+func (s *Sketch) Count() uint64 {
+	return s.count
+}
+
+// Min returns the exact smallest value recorded
+func (s *Sketch) Min() float64 {
+	return s.min
+}
+
+// Max returns the exact largest value recorded
+func (s *Sketch) Max() float64 {
+	return s.max
+}
+
+// Mean returns the exact mean of the values recorded
+func (s *Sketch) Mean() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// StdDev returns the population standard deviation of the values recorded
+func (s *Sketch) StdDev() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	mean := s.Mean()
+	variance := s.sumSq/float64(s.count) - mean*mean
+	// rounding can push a tiny variance below zero
+	return math.Sqrt(max(variance, 0))
+}
+
+// Quantile returns an estimate of the q quantile, eg 0.95 for p95
+func (s *Sketch) Quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return s.min
+	}
+	if q >= 1 {
+		return s.max
+	}
+
+	// the rank of the value we're after, counting from zero
+	rank := uint64(q * float64(s.count-1))
+	if rank < s.zeroCount {
+		return 0
+	}
+
+	seen := s.zeroCount
+	for _, index := range s.sortedIndexes() {
+		seen += s.bins[index]
+		if seen > rank {
+			// clamp so the estimate never falls outside what was actually seen
+			return min(max(s.value(index), s.min), s.max)
+		}
+	}
+	return s.max
+}
+
+// CountBelow estimates how many values were less than or equal to limit
+func (s *Sketch) CountBelow(limit float64) uint64 {
+	if limit >= s.max {
+		return s.count
+	}
+	if limit < minIndexableValue {
+		return s.zeroCount
+	}
+
+	total := s.zeroCount
+	for index, count := range s.bins {
+		if s.value(index) <= limit {
+			total += count
+		}
+	}
+	return total
+}
+
+// index returns the bin a value falls into
+func (s *Sketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) / s.logGamma))
+}
+
+// value returns the representative value of a bin, chosen so that every value in
+// the bin is within the relative accuracy of it
+func (s *Sketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (1 + s.gamma)
+}
+
+// sortedIndexes returns the bin indexes from smallest to largest
+func (s *Sketch) sortedIndexes() []int {
+	indexes := make([]int, 0, len(s.bins))
+	for index := range s.bins {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -0,0 +1,115 @@
+package sketch
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// exactQuantile picks the same rank from sorted values as Quantile does from the bins
+func exactQuantile(sorted []float64, q float64) float64 {
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func TestQuantileErrorBound(t *testing.T) {
+	const accuracy = 0.01
+	random := rand.New(rand.NewPCG(1, 2))
+
+	tests := []struct {
+		name   string
+		values func(i int) float64
+	}{
+		{name: "uniform", values: func(int) float64 { return random.Float64() * 60e9 }},
+		{name: "exponential", values: func(int) float64 { return random.ExpFloat64() * 5e9 }},
+		// spans nine orders of magnitude, the relative error still holds
+		{name: "log uniform", values: func(int) float64 { return math.Pow(10, random.Float64()*9) }},
+		{name: "sequential", values: func(i int) float64 { return float64(i + 1) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New(accuracy)
+			var values []float64
+			for i := range 10000 {
+				value := test.values(i)
+				s.Add(value)
+				values = append(values, value)
+			}
+			slices.Sort(values)
+
+			for _, q := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99, 0.999} {
+				want := exactQuantile(values, q)
+				got := s.Quantile(q)
+				if math.Abs(got-want) > accuracy*want {
+					t.Errorf("q%v: got %v, want %v within %v", q, got, want, accuracy)
+				}
+			}
+			if s.Quantile(0) != values[0] || s.Quantile(1) != values[len(values)-1] {
+				t.Errorf("got min %v and max %v, want %v and %v", s.Quantile(0), s.Quantile(1), values[0], values[len(values)-1])
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	const accuracy = 0.01
+	a, b, all := New(accuracy), New(accuracy), New(accuracy)
+	for i := range 1000 {
+		value := float64(i) * 1e6
+		all.Add(value)
+		if i%3 == 0 {
+			a.Add(value)
+		} else {
+			b.Add(value)
+		}
+	}
+
+	merged := a.Copy()
+	if err := merged.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	// merging is exact, so it's the same as adding every value to one sketch
+	if merged.Count() != all.Count() || merged.Min() != all.Min() || merged.Max() != all.Max() {
+		t.Errorf("got count %d, min %v, max %v, want %d, %v, %v", merged.Count(), merged.Min(), merged.Max(), all.Count(), all.Min(), all.Max())
+	}
+	if math.Abs(merged.Mean()-all.Mean()) > 1e-6*all.Mean() || math.Abs(merged.StdDev()-all.StdDev()) > 1e-6*all.StdDev() {
+		t.Errorf("got mean %v, stddev %v, want %v, %v", merged.Mean(), merged.StdDev(), all.Mean(), all.StdDev())
+	}
+	for _, q := range []float64{0.1, 0.5, 0.9, 0.99} {
+		if merged.Quantile(q) != all.Quantile(q) {
+			t.Errorf("q%v: got %v, want %v", q, merged.Quantile(q), all.Quantile(q))
+		}
+	}
+
+	// the copy merged into, not a
+	if a.Count() != 334 {
+		t.Errorf("merging into a copy changed the original, got count %d", a.Count())
+	}
+
+	// merging an empty sketch changes nothing, merging into one copies the other
+	if err := merged.Merge(New(accuracy)); err != nil || merged.Count() != all.Count() {
+		t.Errorf("merging an empty sketch: got count %d, err %v", merged.Count(), err)
+	}
+	empty := New(accuracy)
+	if err := empty.Merge(b); err != nil || empty.Min() != b.Min() || empty.Max() != b.Max() || empty.Count() != b.Count() {
+		t.Errorf("merging into an empty sketch: got count %d, min %v, max %v, err %v", empty.Count(), empty.Min(), empty.Max(), err)
+	}
+
+	coarse := New(0.02)
+	coarse.Add(1)
+	if err := a.Merge(coarse); err != ErrIncompatible {
+		t.Errorf("merging different accuracies: got %v, want %v", err, ErrIncompatible)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	s := New(0.01)
+	if s.Count() != 0 || s.Min() != 0 || s.Max() != 0 || s.Mean() != 0 || s.StdDev() != 0 || s.CountBelow(1) != 0 {
+		t.Errorf("got count %d, min %v, max %v, mean %v, stddev %v, below %d", s.Count(), s.Min(), s.Max(), s.Mean(), s.StdDev(), s.CountBelow(1))
+	}
+	for _, q := range []float64{0, 0.5, 1} {
+		if got := s.Quantile(q); got != 0 {
+			t.Errorf("q%v: got %v, want 0", q, got)
+		}
+	}
+}
@@ -1,29 +1,71 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
 
 	// Your local packages
 	handlers "fleetsy/internal/api"
+	"fleetsy/internal/config"
 	api "fleetsy/pkg/api"
+	"fleetsy/pkg/fleetsypb"
 )
 
 func main() {
 
+	// settings come from flags, FLEETSY_* environment variables and the config file, in that order
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	// everything logs JSON lines through slog, including the standard log package
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.Level()}))
+	slog.SetDefault(logger)
+	slog.Info("loaded configuration", "file", cfg.File, "settings", cfg)
+
+	// spans are flushed when the server is stopped
+	shutdownTracing, err := handlers.StartTracing(context.Background(), handlers.TracingOptions{
+		Exporter:     cfg.TraceExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		SampleRatio:  cfg.TraceSampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to start tracing: %v", err)
+	}
+
+	// the workers stop and the servers shut down when the root context is cancelled by
+	// SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// open the devices file
-	file, err := os.Open("devices.csv")
+	file, err := os.Open(cfg.DevicesFile)
 
 	if err != nil {
-		log.Fatal("Failed to read devices.csv", err)
+		log.Fatalf("Failed to read %s: %v", cfg.DevicesFile, err)
 	}
 
 	// read all the records, including the column headers
@@ -35,12 +77,38 @@ func main() {
 		fmt.Println("Error reading records")
 	}
 
+	// optional model and group columns say what kind of device each one is and where it belongs
+	// and an optional registered_at column says when it joined the fleet
+	// they're found by name in the header row, which isn't a device itself
+	modelColumn, groupColumn, registeredColumn := -1, -1, -1
+	if len(records) > 0 && records[0][0] == "device_id" {
+		modelColumn = slices.Index(records[0], "model")
+		groupColumn = slices.Index(records[0], "group")
+		registeredColumn = slices.Index(records[0], "registered_at")
+		records = records[1:]
+	}
+
 	// set up the data structures to hold the incoming data
-	deviceHeartbeatMap := make(map[string][]time.Time)
+	devices := make(map[string]handlers.Device)
+	deviceHeartbeatMap := make(map[string][]handlers.Heartbeat)
 	deviceStatsMap := make(map[string][]handlers.DeviceStats)
 	// load the device names and initialize an empty array
 	for _, eachrecord := range records {
-		deviceHeartbeatMap[eachrecord[0]] = []time.Time{}
+		device := handlers.Device{ID: eachrecord[0]}
+		if modelColumn >= 0 {
+			device.Model = eachrecord[modelColumn]
+		}
+		if groupColumn >= 0 {
+			device.Group = eachrecord[groupColumn]
+		}
+		// devices without a valid registration time are registered when the server starts
+		if registeredColumn >= 0 {
+			if registeredAt, err := time.Parse(time.RFC3339, eachrecord[registeredColumn]); err == nil {
+				device.RegisteredAt = registeredAt
+			}
+		}
+		devices[eachrecord[0]] = device
+		deviceHeartbeatMap[eachrecord[0]] = []handlers.Heartbeat{}
 		deviceStatsMap[eachrecord[0]] = []handlers.DeviceStats{}
 	}
 
@@ -48,23 +116,96 @@ func main() {
 	file.Close()
 
 	// Initialize api server
-	apiServer := handlers.NewServer(deviceHeartbeatMap, deviceStatsMap)
+	apiServer := handlers.NewServer(devices, deviceHeartbeatMap, deviceStatsMap, handlers.Options{
+		MaxClockSkew:        cfg.MaxClockSkew,
+		CorrectClockSkew:    cfg.CorrectClockSkew,
+		AnomalyThreshold:    cfg.AnomalyThreshold,
+		UptimeMode:          cfg.UptimeMode,
+		UptimeTolerance:     cfg.UptimeTolerance,
+		PresenceGrace:       cfg.PresenceGrace,
+		OfflineAfterMissed:  cfg.OfflineAfterMissed,
+		WebhookMaxAttempts:  cfg.WebhookMaxAttempts,
+		WebhookTimeout:      cfg.WebhookTimeout,
+		MetricsDeviceGauges: cfg.MetricsDeviceGauges,
+		MetricsMaxDevices:   cfg.MetricsMaxDevices,
+		MetricsDeviceGroups: cfg.MetricsDeviceGroups,
+		Retention:           cfg.Retention,
+	})
+
+	// watch for devices that stop sending heartbeats
+	go apiServer.RunPresence(ctx, cfg.PresenceCheckInterval)
+	// deliver webhook notifications
+	go apiServer.RunWebhooks(ctx)
+	// evaluate alert rules
+	go apiServer.RunAlerts(ctx, cfg.AlertEvalInterval)
+	// drop old records if there's a retention period
+	if cfg.Retention > 0 {
+		go apiServer.RunRetention(ctx)
+	}
+
+	// start the UDP heartbeat listener if it was requested
+	if cfg.UDPAddr != "" {
+		go func() {
+			if err := apiServer.ListenHeartbeatUDP(ctx, cfg.UDPAddr, []byte(cfg.UDPSecret)); err != nil {
+				log.Fatalf("UDP heartbeat listener failed: %v", err)
+			}
+		}()
+	}
+
+	// start the gRPC api if it was requested
+	var grpcServer *grpc.Server
+	if cfg.GRPCAddr != "" {
+		listener, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		// otelgrpc continues the caller's trace from the grpc metadata
+		grpcServer = grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+		fleetsypb.RegisterFleetsyServer(grpcServer, handlers.NewGRPCServer(apiServer))
+		go func() {
+			log.Printf("gRPC api is running on %s\n", listener.Addr())
+			if err := grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				log.Fatalf("gRPC api failed: %v", err)
+			}
+		}()
+	}
 
 	// initialize api router
 	apiRouter := chi.NewRouter()
+	// devices can hold a websocket open instead of posting heartbeats
+	if cfg.WebSocket {
+		apiRouter.Get("/devices/{device_id}/ws", apiServer.DeviceWebSocket)
+	}
 	// register the handlers
-	apiHandler := api.HandlerFromMux(apiServer, apiRouter)
+	// compressed bodies are decompressed before the handlers see them
+	apiHandler := api.HandlerWithOptions(apiServer, api.ChiServerOptions{
+		BaseRouter:  apiRouter,
+		Middlewares: []api.MiddlewareFunc{handlers.DecompressRequestBody(cfg.MaxBodyBytes)},
+	})
 
 	// create main router so we can put the handlers on the right path
 	mainRouter := chi.NewRouter()
-	mainRouter.Use(middleware.Logger)
-	mainRouter.Use(middleware.Recoverer)
+	mainRouter.Use(middleware.RequestID)
+	mainRouter.Use(handlers.TraceRequests)
+	mainRouter.Use(handlers.RequestLogger(logger, cfg.LogHeartbeatSample))
+	mainRouter.Use(handlers.RecoverPanics)
+	mainRouter.Use(apiServer.InstrumentRequests)
 
 	// healthcheck endpoint
 	mainRouter.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("pong"))
 	})
 
+	// liveness and readiness probes, /readyz fails until the devices are loaded and the
+	// background workers are running
+	mainRouter.Get("/healthz", apiServer.Healthz)
+	mainRouter.Get("/readyz", apiServer.Readyz)
+
+	// prometheus metrics
+	if cfg.Metrics {
+		mainRouter.Handle("/metrics", apiServer.MetricsHandler())
+	}
+
 	// load the api handlers to the proper path
 	mainRouter.Mount("/api/v1", apiHandler)
 
@@ -80,10 +221,52 @@ func main() {
 	// }
 
 	// start the server
-	port := 8080
-	log.Printf("Server is running on port %d\n", port)
-	httpErr := http.ListenAndServe(fmt.Sprintf(":%d", port), mainRouter)
-	if httpErr != nil {
-		log.Fatalf("Failed to start server: %v", httpErr)
+	server := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           mainRouter,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	httpErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server is running on %s\n", cfg.ListenAddr)
+		httpErr <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-httpErr:
+		log.Fatalf("Failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+	// a second signal kills the server straight away
+	stop()
+
+	// finish the requests in flight, then flush the spans they made, all within the timeout
+	slog.Info("shutting down", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shut down the HTTP server", "error", err)
+	}
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush spans", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// stopGRPC lets the gRPC calls in flight finish, cutting them off if ctx is done first
+func stopGRPC(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Error("gRPC calls still running after the shutdown timeout, stopping them")
+		grpcServer.Stop()
 	}
 }
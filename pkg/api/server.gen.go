@@ -12,77 +12,1664 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/oapi-codegen/runtime"
 )
 
+// UploadTimeDistribution the spread of upload times, every duration is returned as a time duration string. Eg: 5m10s
+type UploadTimeDistribution struct {
+	// Count the number of uploads
+	Count int `json:"count"`
+
+	// Histogram cumulative counts of uploads that took at most le
+	Histogram []struct {
+		Count int `json:"count"`
+
+		// Le bucket upper bound. Eg: 30s or +Inf
+		Le string `json:"le"`
+	} `json:"histogram"`
+	Max    string `json:"max"`
+	Min    string `json:"min"`
+	P50    string `json:"p50"`
+	P90    string `json:"p90"`
+	P95    string `json:"p95"`
+	P99    string `json:"p99"`
+	StdDev string `json:"std_dev"`
+}
+
+// AlertPathParam defines model for AlertPathParam.
+type AlertPathParam = string
+
+// FromQueryParam defines model for FromQueryParam.
+type FromQueryParam = time.Time
+
+// GroupPathParam defines model for GroupPathParam.
+type GroupPathParam = string
+
+// MetricPathParam defines model for MetricPathParam.
+type MetricPathParam = string
+
+// ModelPathParam defines model for ModelPathParam.
+type ModelPathParam = string
+
+// NotificationPathParam defines model for NotificationPathParam.
+type NotificationPathParam = string
+
+// RoutePathParam defines model for RoutePathParam.
+type RoutePathParam = string
+
+// RulePathParam defines model for RulePathParam.
+type RulePathParam = string
+
+// SLOPathParam defines model for SLOPathParam.
+type SLOPathParam = string
+
+// SilencePathParam defines model for SilencePathParam.
+type SilencePathParam = string
+
+// ToQueryParam defines model for ToQueryParam.
+type ToQueryParam = time.Time
+
+// WebhookPathParam defines model for WebhookPathParam.
+type WebhookPathParam = string
+
+// GetAlertsParams defines parameters for GetAlerts.
+type GetAlertsParams struct {
+	// State only return alerts in this state
+	State *string `form:"state,omitempty" json:"state,omitempty"`
+
+	// Rule only return alerts for this rule
+	Rule *string `form:"rule,omitempty" json:"rule,omitempty"`
+
+	// DeviceId only return alerts for this device
+	DeviceId *string `form:"device_id,omitempty" json:"device_id,omitempty"`
+}
+
+// GetDevicesDeviceIdAnomaliesParams defines parameters for GetDevicesDeviceIdAnomalies.
+type GetDevicesDeviceIdAnomaliesParams struct {
+	// Metric only return anomalies in this series, upload_time or heartbeat_interval
+	Metric *string `form:"metric,omitempty" json:"metric,omitempty"`
+
+	// Severity warning returns every anomaly, critical only the critical ones
+	Severity *string `form:"severity,omitempty" json:"severity,omitempty"`
+
+	// From only include data sent at or after this time
+	From *FromQueryParam `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only include data sent before this time
+	To *ToQueryParam `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetDevicesDeviceIdMetricsMetricParams defines parameters for GetDevicesDeviceIdMetricsMetric.
+type GetDevicesDeviceIdMetricsMetricParams struct {
+	// From only include data sent at or after this time
+	From *FromQueryParam `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only include data sent before this time
+	To *ToQueryParam `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetDevicesDeviceIdPresenceEventsParams defines parameters for GetDevicesDeviceIdPresenceEvents.
+type GetDevicesDeviceIdPresenceEventsParams struct {
+	// From only include data sent at or after this time
+	From *FromQueryParam `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only include data sent before this time
+	To *ToQueryParam `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetDevicesDeviceIdSeriesParams defines parameters for GetDevicesDeviceIdSeries.
+type GetDevicesDeviceIdSeriesParams struct {
+	// Metric the series to return, uptime or upload_time
+	Metric string `form:"metric" json:"metric"`
+
+	// Step bucket size as a duration. Eg: 15m, 1h
+	Step string `form:"step" json:"step"`
+
+	// From only include data sent at or after this time
+	From *FromQueryParam `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only include data sent before this time
+	To *ToQueryParam `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetDevicesDeviceIdStatsParams defines parameters for GetDevicesDeviceIdStats.
+type GetDevicesDeviceIdStatsParams struct {
+	// NumericDurations also return every duration as nanoseconds, milliseconds and an ISO-8601 duration in durations
+	NumericDurations *bool `form:"numeric_durations,omitempty" json:"numeric_durations,omitempty"`
+}
+
+// GetFleetStatsParams defines parameters for GetFleetStats.
+type GetFleetStatsParams struct {
+	// Sort column to sort the rows by: device_id, uptime, avg_upload_time, heartbeats, uploads or last_heartbeat. Defaults to device_id
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Order asc or desc. Defaults to asc
+	Order *string `form:"order,omitempty" json:"order,omitempty"`
+
+	// Limit the most rows to return, up to 1000. Defaults to 50
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset the number of rows to skip
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Health only return rows in this health state
+	Health *string `form:"health,omitempty" json:"health,omitempty"`
+
+	// Group only include devices in this group
+	Group *string `form:"group,omitempty" json:"group,omitempty"`
+
+	// From only include data sent at or after this time
+	From *FromQueryParam `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only include data sent before this time
+	To *ToQueryParam `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetNotificationsParams defines parameters for GetNotifications.
+type GetNotificationsParams struct {
+	// Status only return notifications with this status
+	Status *string `form:"status,omitempty" json:"status,omitempty"`
+
+	// Webhook only return notifications for this webhook
+	Webhook *string `form:"webhook,omitempty" json:"webhook,omitempty"`
+
+	// Route only return notifications for this alert route
+	Route *string `form:"route,omitempty" json:"route,omitempty"`
+}
+
+// GetSilencesParams defines parameters for GetSilences.
+type GetSilencesParams struct {
+	// State only return silences in this state
+	State *string `form:"state,omitempty" json:"state,omitempty"`
+}
+
+// GetSlosParams defines parameters for GetSlos.
+type GetSlosParams struct {
+	// To evaluate the window ending at this time instead of now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetSlosAtRiskParams defines parameters for GetSlosAtRisk.
+type GetSlosAtRiskParams struct {
+	// To evaluate the window ending at this time instead of now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetSlosSloParams defines parameters for GetSlosSlo.
+type GetSlosSloParams struct {
+	// To evaluate the window ending at this time instead of now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
-	// (POST /devices/{device_id}/heartbeat)
-	PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Request, deviceId string)
+	// (GET /alerts)
+	GetAlerts(w http.ResponseWriter, r *http.Request, params GetAlertsParams)
+
+	// (POST /alerts/{alert}/acknowledge)
+	PostAlertsAlertAcknowledge(w http.ResponseWriter, r *http.Request, alert AlertPathParam)
+
+	// (GET /devices/{device_id})
+	GetDevicesDeviceId(w http.ResponseWriter, r *http.Request, deviceId string)
+
+	// (GET /devices/{device_id}/anomalies)
+	GetDevicesDeviceIdAnomalies(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdAnomaliesParams)
+
+	// (POST /devices/{device_id}/heartbeat)
+	PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Request, deviceId string)
+
+	// (GET /devices/{device_id}/metrics)
+	GetDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request, deviceId string)
+
+	// (POST /devices/{device_id}/metrics)
+	PostDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request, deviceId string)
+
+	// (GET /devices/{device_id}/metrics/{metric})
+	GetDevicesDeviceIdMetricsMetric(w http.ResponseWriter, r *http.Request, deviceId string, metric MetricPathParam, params GetDevicesDeviceIdMetricsMetricParams)
+
+	// (GET /devices/{device_id}/presence/events)
+	GetDevicesDeviceIdPresenceEvents(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdPresenceEventsParams)
+
+	// (GET /devices/{device_id}/series)
+	GetDevicesDeviceIdSeries(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdSeriesParams)
+
+	// (GET /devices/{device_id}/stats)
+	GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdStatsParams)
+
+	// (POST /devices/{device_id}/stats)
+	PostDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string)
+
+	// (GET /fleet/stats)
+	GetFleetStats(w http.ResponseWriter, r *http.Request, params GetFleetStatsParams)
+
+	// (GET /groups/{group}/stats)
+	GetGroupsGroupStats(w http.ResponseWriter, r *http.Request, group GroupPathParam)
+
+	// (GET /models/{model}/metrics)
+	GetModelsModelMetrics(w http.ResponseWriter, r *http.Request, model ModelPathParam)
+
+	// (PUT /models/{model}/metrics/{metric})
+	PutModelsModelMetricsMetric(w http.ResponseWriter, r *http.Request, model ModelPathParam, metric MetricPathParam)
+
+	// (GET /notifications)
+	GetNotifications(w http.ResponseWriter, r *http.Request, params GetNotificationsParams)
+
+	// (POST /notifications/{notification}/redeliver)
+	PostNotificationsNotificationRedeliver(w http.ResponseWriter, r *http.Request, notification NotificationPathParam)
+
+	// (GET /routes)
+	GetRoutes(w http.ResponseWriter, r *http.Request)
+
+	// (DELETE /routes/{route})
+	DeleteRoutesRoute(w http.ResponseWriter, r *http.Request, route RoutePathParam)
+
+	// (GET /routes/{route})
+	GetRoutesRoute(w http.ResponseWriter, r *http.Request, route RoutePathParam)
+
+	// (PUT /routes/{route})
+	PutRoutesRoute(w http.ResponseWriter, r *http.Request, route RoutePathParam)
+
+	// (POST /routes/{route}/test)
+	PostRoutesRouteTest(w http.ResponseWriter, r *http.Request, route RoutePathParam)
+
+	// (GET /rules)
+	GetRules(w http.ResponseWriter, r *http.Request)
+
+	// (DELETE /rules/{rule})
+	DeleteRulesRule(w http.ResponseWriter, r *http.Request, rule RulePathParam)
+
+	// (GET /rules/{rule})
+	GetRulesRule(w http.ResponseWriter, r *http.Request, rule RulePathParam)
+
+	// (PUT /rules/{rule})
+	PutRulesRule(w http.ResponseWriter, r *http.Request, rule RulePathParam)
+
+	// (GET /rules/{rule}/history)
+	GetRulesRuleHistory(w http.ResponseWriter, r *http.Request, rule RulePathParam)
+
+	// (GET /silences)
+	GetSilences(w http.ResponseWriter, r *http.Request, params GetSilencesParams)
+
+	// (POST /silences)
+	PostSilences(w http.ResponseWriter, r *http.Request)
+
+	// (DELETE /silences/{silence})
+	DeleteSilencesSilence(w http.ResponseWriter, r *http.Request, silence SilencePathParam)
+
+	// (GET /silences/{silence})
+	GetSilencesSilence(w http.ResponseWriter, r *http.Request, silence SilencePathParam)
+
+	// (GET /slos)
+	GetSlos(w http.ResponseWriter, r *http.Request, params GetSlosParams)
+
+	// (GET /slos/at-risk)
+	GetSlosAtRisk(w http.ResponseWriter, r *http.Request, params GetSlosAtRiskParams)
+
+	// (DELETE /slos/{slo})
+	DeleteSlosSlo(w http.ResponseWriter, r *http.Request, slo SLOPathParam)
+
+	// (GET /slos/{slo})
+	GetSlosSlo(w http.ResponseWriter, r *http.Request, slo SLOPathParam, params GetSlosSloParams)
+
+	// (PUT /slos/{slo})
+	PutSlosSlo(w http.ResponseWriter, r *http.Request, slo SLOPathParam)
+
+	// (GET /webhooks)
+	GetWebhooks(w http.ResponseWriter, r *http.Request)
+
+	// (DELETE /webhooks/{webhook})
+	DeleteWebhooksWebhook(w http.ResponseWriter, r *http.Request, webhook WebhookPathParam)
+
+	// (PUT /webhooks/{webhook})
+	PutWebhooksWebhook(w http.ResponseWriter, r *http.Request, webhook WebhookPathParam)
+}
+
+type Error struct {
+	// Code Error code
+	Code int32 `json:"code"`
+
+	// Message Error message
+	Message string `json:"message"`
+}
+
+// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+
+type Unimplemented struct{}
+
+// (GET /alerts)
+func (_ Unimplemented) GetAlerts(w http.ResponseWriter, r *http.Request, params GetAlertsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /alerts/{alert}/acknowledge)
+func (_ Unimplemented) PostAlertsAlertAcknowledge(w http.ResponseWriter, r *http.Request, alert AlertPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /devices/{device_id})
+func (_ Unimplemented) GetDevicesDeviceId(w http.ResponseWriter, r *http.Request, deviceId string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /devices/{device_id}/anomalies)
+func (_ Unimplemented) GetDevicesDeviceIdAnomalies(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdAnomaliesParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /devices/{device_id}/heartbeat)
+func (_ Unimplemented) PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Request, deviceId string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /devices/{device_id}/metrics)
+func (_ Unimplemented) GetDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request, deviceId string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /devices/{device_id}/metrics)
+func (_ Unimplemented) PostDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request, deviceId string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /devices/{device_id}/metrics/{metric})
+func (_ Unimplemented) GetDevicesDeviceIdMetricsMetric(w http.ResponseWriter, r *http.Request, deviceId string, metric MetricPathParam, params GetDevicesDeviceIdMetricsMetricParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /devices/{device_id}/presence/events)
+func (_ Unimplemented) GetDevicesDeviceIdPresenceEvents(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdPresenceEventsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /devices/{device_id}/series)
+func (_ Unimplemented) GetDevicesDeviceIdSeries(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdSeriesParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /devices/{device_id}/stats)
+func (_ Unimplemented) GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string, params GetDevicesDeviceIdStatsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /devices/{device_id}/stats)
+func (_ Unimplemented) PostDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /fleet/stats)
+func (_ Unimplemented) GetFleetStats(w http.ResponseWriter, r *http.Request, params GetFleetStatsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /groups/{group}/stats)
+func (_ Unimplemented) GetGroupsGroupStats(w http.ResponseWriter, r *http.Request, group GroupPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /models/{model}/metrics)
+func (_ Unimplemented) GetModelsModelMetrics(w http.ResponseWriter, r *http.Request, model ModelPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (PUT /models/{model}/metrics/{metric})
+func (_ Unimplemented) PutModelsModelMetricsMetric(w http.ResponseWriter, r *http.Request, model ModelPathParam, metric MetricPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /notifications)
+func (_ Unimplemented) GetNotifications(w http.ResponseWriter, r *http.Request, params GetNotificationsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /notifications/{notification}/redeliver)
+func (_ Unimplemented) PostNotificationsNotificationRedeliver(w http.ResponseWriter, r *http.Request, notification NotificationPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /routes)
+func (_ Unimplemented) GetRoutes(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /routes/{route})
+func (_ Unimplemented) DeleteRoutesRoute(w http.ResponseWriter, r *http.Request, route RoutePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /routes/{route})
+func (_ Unimplemented) GetRoutesRoute(w http.ResponseWriter, r *http.Request, route RoutePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (PUT /routes/{route})
+func (_ Unimplemented) PutRoutesRoute(w http.ResponseWriter, r *http.Request, route RoutePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /routes/{route}/test)
+func (_ Unimplemented) PostRoutesRouteTest(w http.ResponseWriter, r *http.Request, route RoutePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /rules)
+func (_ Unimplemented) GetRules(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /rules/{rule})
+func (_ Unimplemented) DeleteRulesRule(w http.ResponseWriter, r *http.Request, rule RulePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /rules/{rule})
+func (_ Unimplemented) GetRulesRule(w http.ResponseWriter, r *http.Request, rule RulePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (PUT /rules/{rule})
+func (_ Unimplemented) PutRulesRule(w http.ResponseWriter, r *http.Request, rule RulePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /rules/{rule}/history)
+func (_ Unimplemented) GetRulesRuleHistory(w http.ResponseWriter, r *http.Request, rule RulePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /silences)
+func (_ Unimplemented) GetSilences(w http.ResponseWriter, r *http.Request, params GetSilencesParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /silences)
+func (_ Unimplemented) PostSilences(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /silences/{silence})
+func (_ Unimplemented) DeleteSilencesSilence(w http.ResponseWriter, r *http.Request, silence SilencePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /silences/{silence})
+func (_ Unimplemented) GetSilencesSilence(w http.ResponseWriter, r *http.Request, silence SilencePathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /slos)
+func (_ Unimplemented) GetSlos(w http.ResponseWriter, r *http.Request, params GetSlosParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /slos/at-risk)
+func (_ Unimplemented) GetSlosAtRisk(w http.ResponseWriter, r *http.Request, params GetSlosAtRiskParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /slos/{slo})
+func (_ Unimplemented) DeleteSlosSlo(w http.ResponseWriter, r *http.Request, slo SLOPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /slos/{slo})
+func (_ Unimplemented) GetSlosSlo(w http.ResponseWriter, r *http.Request, slo SLOPathParam, params GetSlosSloParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (PUT /slos/{slo})
+func (_ Unimplemented) PutSlosSlo(w http.ResponseWriter, r *http.Request, slo SLOPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /webhooks)
+func (_ Unimplemented) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /webhooks/{webhook})
+func (_ Unimplemented) DeleteWebhooksWebhook(w http.ResponseWriter, r *http.Request, webhook WebhookPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (PUT /webhooks/{webhook})
+func (_ Unimplemented) PutWebhooksWebhook(w http.ResponseWriter, r *http.Request, webhook WebhookPathParam) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// GetAlerts operation middleware
+func (siw *ServerInterfaceWrapper) GetAlerts(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAlertsParams
+
+	// ------------- Optional query parameter "state" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "state", r.URL.Query(), &params.State)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "state", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "rule" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rule", r.URL.Query(), &params.Rule)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rule", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "device_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "device_id", r.URL.Query(), &params.DeviceId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAlerts(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostAlertsAlertAcknowledge operation middleware
+func (siw *ServerInterfaceWrapper) PostAlertsAlertAcknowledge(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "alert" -------------
+	var alert AlertPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "alert", chi.URLParam(r, "alert"), &alert, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "alert", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAlertsAlertAcknowledge(w, r, alert)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDevicesDeviceId operation middleware
+func (siw *ServerInterfaceWrapper) GetDevicesDeviceId(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDevicesDeviceId(w, r, deviceId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDevicesDeviceIdAnomalies operation middleware
+func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdAnomalies(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDevicesDeviceIdAnomaliesParams
+
+	// ------------- Optional query parameter "metric" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "metric", r.URL.Query(), &params.Metric)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "metric", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "severity" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "severity", r.URL.Query(), &params.Severity)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "severity", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDevicesDeviceIdAnomalies(w, r, deviceId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostDevicesDeviceIdHeartbeat operation middleware
+func (siw *ServerInterfaceWrapper) PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostDevicesDeviceIdHeartbeat(w, r, deviceId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDevicesDeviceIdMetrics operation middleware
+func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDevicesDeviceIdMetrics(w, r, deviceId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostDevicesDeviceIdMetrics operation middleware
+func (siw *ServerInterfaceWrapper) PostDevicesDeviceIdMetrics(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostDevicesDeviceIdMetrics(w, r, deviceId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDevicesDeviceIdMetricsMetric operation middleware
+func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdMetricsMetric(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "metric" -------------
+	var metric MetricPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "metric", chi.URLParam(r, "metric"), &metric, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "metric", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDevicesDeviceIdMetricsMetricParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDevicesDeviceIdMetricsMetric(w, r, deviceId, metric, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDevicesDeviceIdPresenceEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdPresenceEvents(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDevicesDeviceIdPresenceEventsParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDevicesDeviceIdPresenceEvents(w, r, deviceId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDevicesDeviceIdSeries operation middleware
+func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdSeries(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDevicesDeviceIdSeriesParams
+
+	// ------------- Required query parameter "metric" -------------
+
+	if paramValue := r.URL.Query().Get("metric"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "metric"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "metric", r.URL.Query(), &params.Metric)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "metric", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "step" -------------
+
+	if paramValue := r.URL.Query().Get("step"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "step"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "step", r.URL.Query(), &params.Step)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "step", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDevicesDeviceIdSeries(w, r, deviceId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDevicesDeviceIdStats operation middleware
+func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDevicesDeviceIdStatsParams
+
+	// ------------- Optional query parameter "numeric_durations" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "numeric_durations", r.URL.Query(), &params.NumericDurations)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "numeric_durations", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDevicesDeviceIdStats(w, r, deviceId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostDevicesDeviceIdStats operation middleware
+func (siw *ServerInterfaceWrapper) PostDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "device_id" -------------
+	var deviceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostDevicesDeviceIdStats(w, r, deviceId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetFleetStats operation middleware
+func (siw *ServerInterfaceWrapper) GetFleetStats(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetFleetStatsParams
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", r.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "order", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "health" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "health", r.URL.Query(), &params.Health)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "health", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "group" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "group", r.URL.Query(), &params.Group)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "group", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFleetStats(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetGroupsGroupStats operation middleware
+func (siw *ServerInterfaceWrapper) GetGroupsGroupStats(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "group" -------------
+	var group GroupPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "group", chi.URLParam(r, "group"), &group, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "group", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetGroupsGroupStats(w, r, group)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetModelsModelMetrics operation middleware
+func (siw *ServerInterfaceWrapper) GetModelsModelMetrics(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "model" -------------
+	var model ModelPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "model", chi.URLParam(r, "model"), &model, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "model", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetModelsModelMetrics(w, r, model)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PutModelsModelMetricsMetric operation middleware
+func (siw *ServerInterfaceWrapper) PutModelsModelMetricsMetric(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "model" -------------
+	var model ModelPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "model", chi.URLParam(r, "model"), &model, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "model", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "metric" -------------
+	var metric MetricPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "metric", chi.URLParam(r, "metric"), &metric, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "metric", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutModelsModelMetricsMetric(w, r, model, metric)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetNotifications operation middleware
+func (siw *ServerInterfaceWrapper) GetNotifications(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetNotificationsParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "webhook" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "webhook", r.URL.Query(), &params.Webhook)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "webhook", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "route" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "route", r.URL.Query(), &params.Route)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "route", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetNotifications(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostNotificationsNotificationRedeliver operation middleware
+func (siw *ServerInterfaceWrapper) PostNotificationsNotificationRedeliver(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "notification" -------------
+	var notification NotificationPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "notification", chi.URLParam(r, "notification"), &notification, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "notification", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostNotificationsNotificationRedeliver(w, r, notification)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetRoutes operation middleware
+func (siw *ServerInterfaceWrapper) GetRoutes(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRoutes(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteRoutesRoute operation middleware
+func (siw *ServerInterfaceWrapper) DeleteRoutesRoute(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "route" -------------
+	var route RoutePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "route", chi.URLParam(r, "route"), &route, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "route", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteRoutesRoute(w, r, route)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetRoutesRoute operation middleware
+func (siw *ServerInterfaceWrapper) GetRoutesRoute(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "route" -------------
+	var route RoutePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "route", chi.URLParam(r, "route"), &route, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "route", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRoutesRoute(w, r, route)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PutRoutesRoute operation middleware
+func (siw *ServerInterfaceWrapper) PutRoutesRoute(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "route" -------------
+	var route RoutePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "route", chi.URLParam(r, "route"), &route, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "route", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutRoutesRoute(w, r, route)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostRoutesRouteTest operation middleware
+func (siw *ServerInterfaceWrapper) PostRoutesRouteTest(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "route" -------------
+	var route RoutePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "route", chi.URLParam(r, "route"), &route, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "route", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostRoutesRouteTest(w, r, route)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetRules operation middleware
+func (siw *ServerInterfaceWrapper) GetRules(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRules(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteRulesRule operation middleware
+func (siw *ServerInterfaceWrapper) DeleteRulesRule(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "rule" -------------
+	var rule RulePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "rule", chi.URLParam(r, "rule"), &rule, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rule", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteRulesRule(w, r, rule)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetRulesRule operation middleware
+func (siw *ServerInterfaceWrapper) GetRulesRule(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "rule" -------------
+	var rule RulePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "rule", chi.URLParam(r, "rule"), &rule, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rule", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRulesRule(w, r, rule)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PutRulesRule operation middleware
+func (siw *ServerInterfaceWrapper) PutRulesRule(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "rule" -------------
+	var rule RulePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "rule", chi.URLParam(r, "rule"), &rule, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rule", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutRulesRule(w, r, rule)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetRulesRuleHistory operation middleware
+func (siw *ServerInterfaceWrapper) GetRulesRuleHistory(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "rule" -------------
+	var rule RulePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "rule", chi.URLParam(r, "rule"), &rule, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rule", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRulesRuleHistory(w, r, rule)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSilences operation middleware
+func (siw *ServerInterfaceWrapper) GetSilences(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSilencesParams
 
-	// (GET /devices/{device_id}/stats)
-	GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string)
+	// ------------- Optional query parameter "state" -------------
 
-	// (POST /devices/{device_id}/stats)
-	PostDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string)
+	err = runtime.BindQueryParameter("form", true, false, "state", r.URL.Query(), &params.State)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "state", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSilences(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-type Error struct {
-	// Code Error code
-	Code int32 `json:"code"`
+// PostSilences operation middleware
+func (siw *ServerInterfaceWrapper) PostSilences(w http.ResponseWriter, r *http.Request) {
 
-	// Message Error message
-	Message string `json:"message"`
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostSilences(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+// DeleteSilencesSilence operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSilencesSilence(w http.ResponseWriter, r *http.Request) {
 
-type Unimplemented struct{}
+	var err error
 
-// (POST /devices/{device_id}/heartbeat)
-func (_ Unimplemented) PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Request, deviceId string) {
-	w.WriteHeader(http.StatusNotImplemented)
+	// ------------- Path parameter "silence" -------------
+	var silence SilencePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "silence", chi.URLParam(r, "silence"), &silence, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "silence", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteSilencesSilence(w, r, silence)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-// (GET /devices/{device_id}/stats)
-func (_ Unimplemented) GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string) {
-	w.WriteHeader(http.StatusNotImplemented)
+// GetSilencesSilence operation middleware
+func (siw *ServerInterfaceWrapper) GetSilencesSilence(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "silence" -------------
+	var silence SilencePathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "silence", chi.URLParam(r, "silence"), &silence, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "silence", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSilencesSilence(w, r, silence)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-// (POST /devices/{device_id}/stats)
-func (_ Unimplemented) PostDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request, deviceId string) {
-	w.WriteHeader(http.StatusNotImplemented)
+// GetSlos operation middleware
+func (siw *ServerInterfaceWrapper) GetSlos(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSlosParams
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSlos(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+// GetSlosAtRisk operation middleware
+func (siw *ServerInterfaceWrapper) GetSlosAtRisk(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSlosAtRiskParams
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSlosAtRisk(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-type MiddlewareFunc func(http.Handler) http.Handler
+// DeleteSlosSlo operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSlosSlo(w http.ResponseWriter, r *http.Request) {
 
-// PostDevicesDeviceIdHeartbeat operation middleware
-func (siw *ServerInterfaceWrapper) PostDevicesDeviceIdHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// ------------- Path parameter "slo" -------------
+	var slo SLOPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "slo", chi.URLParam(r, "slo"), &slo, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "slo", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteSlosSlo(w, r, slo)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSlosSlo operation middleware
+func (siw *ServerInterfaceWrapper) GetSlosSlo(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
-	// ------------- Path parameter "device_id" -------------
-	var deviceId string
+	// ------------- Path parameter "slo" -------------
+	var slo SLOPathParam
 
-	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "slo", chi.URLParam(r, "slo"), &slo, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "slo", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSlosSloParams
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostDevicesDeviceIdHeartbeat(w, r, deviceId)
+		siw.Handler.GetSlosSlo(w, r, slo, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -92,22 +1679,22 @@ func (siw *ServerInterfaceWrapper) PostDevicesDeviceIdHeartbeat(w http.ResponseW
 	handler.ServeHTTP(w, r)
 }
 
-// GetDevicesDeviceIdStats operation middleware
-func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request) {
+// PutSlosSlo operation middleware
+func (siw *ServerInterfaceWrapper) PutSlosSlo(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
-	// ------------- Path parameter "device_id" -------------
-	var deviceId string
+	// ------------- Path parameter "slo" -------------
+	var slo SLOPathParam
 
-	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "slo", chi.URLParam(r, "slo"), &slo, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "slo", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetDevicesDeviceIdStats(w, r, deviceId)
+		siw.Handler.PutSlosSlo(w, r, slo)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -117,22 +1704,61 @@ func (siw *ServerInterfaceWrapper) GetDevicesDeviceIdStats(w http.ResponseWriter
 	handler.ServeHTTP(w, r)
 }
 
-// PostDevicesDeviceIdStats operation middleware
-func (siw *ServerInterfaceWrapper) PostDevicesDeviceIdStats(w http.ResponseWriter, r *http.Request) {
+// GetWebhooks operation middleware
+func (siw *ServerInterfaceWrapper) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetWebhooks(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteWebhooksWebhook operation middleware
+func (siw *ServerInterfaceWrapper) DeleteWebhooksWebhook(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
-	// ------------- Path parameter "device_id" -------------
-	var deviceId string
+	// ------------- Path parameter "webhook" -------------
+	var webhook WebhookPathParam
 
-	err = runtime.BindStyledParameterWithOptions("simple", "device_id", chi.URLParam(r, "device_id"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "webhook", chi.URLParam(r, "webhook"), &webhook, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "device_id", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "webhook", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostDevicesDeviceIdStats(w, r, deviceId)
+		siw.Handler.DeleteWebhooksWebhook(w, r, webhook)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PutWebhooksWebhook operation middleware
+func (siw *ServerInterfaceWrapper) PutWebhooksWebhook(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "webhook" -------------
+	var webhook WebhookPathParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "webhook", chi.URLParam(r, "webhook"), &webhook, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "webhook", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutWebhooksWebhook(w, r, webhook)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -255,15 +1881,126 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/alerts", wrapper.GetAlerts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/alerts/{alert}/acknowledge", wrapper.PostAlertsAlertAcknowledge)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/devices/{device_id}", wrapper.GetDevicesDeviceId)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/devices/{device_id}/anomalies", wrapper.GetDevicesDeviceIdAnomalies)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/devices/{device_id}/heartbeat", wrapper.PostDevicesDeviceIdHeartbeat)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/devices/{device_id}/metrics", wrapper.GetDevicesDeviceIdMetrics)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/devices/{device_id}/metrics", wrapper.PostDevicesDeviceIdMetrics)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/devices/{device_id}/metrics/{metric}", wrapper.GetDevicesDeviceIdMetricsMetric)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/devices/{device_id}/presence/events", wrapper.GetDevicesDeviceIdPresenceEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/devices/{device_id}/series", wrapper.GetDevicesDeviceIdSeries)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/devices/{device_id}/stats", wrapper.GetDevicesDeviceIdStats)
 	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/devices/{device_id}/stats", wrapper.PostDevicesDeviceIdStats)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/fleet/stats", wrapper.GetFleetStats)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/groups/{group}/stats", wrapper.GetGroupsGroupStats)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/models/{model}/metrics", wrapper.GetModelsModelMetrics)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/models/{model}/metrics/{metric}", wrapper.PutModelsModelMetricsMetric)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/notifications", wrapper.GetNotifications)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/notifications/{notification}/redeliver", wrapper.PostNotificationsNotificationRedeliver)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/routes", wrapper.GetRoutes)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/routes/{route}", wrapper.DeleteRoutesRoute)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/routes/{route}", wrapper.GetRoutesRoute)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/routes/{route}", wrapper.PutRoutesRoute)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/routes/{route}/test", wrapper.PostRoutesRouteTest)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/rules", wrapper.GetRules)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/rules/{rule}", wrapper.DeleteRulesRule)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/rules/{rule}", wrapper.GetRulesRule)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/rules/{rule}", wrapper.PutRulesRule)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/rules/{rule}/history", wrapper.GetRulesRuleHistory)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/silences", wrapper.GetSilences)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/silences", wrapper.PostSilences)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/silences/{silence}", wrapper.DeleteSilencesSilence)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/silences/{silence}", wrapper.GetSilencesSilence)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/slos", wrapper.GetSlos)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/slos/at-risk", wrapper.GetSlosAtRisk)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/slos/{slo}", wrapper.DeleteSlosSlo)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/slos/{slo}", wrapper.GetSlosSlo)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/slos/{slo}", wrapper.PutSlosSlo)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/webhooks", wrapper.GetWebhooks)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/webhooks/{webhook}", wrapper.DeleteWebhooksWebhook)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/webhooks/{webhook}", wrapper.PutWebhooksWebhook)
+	})
 
 	return r
 }
@@ -271,21 +2008,130 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/9SW33PbNgzH/xUetkdVspt2XfS0bGm3PLSXS65PvVyOEWGZnURyBGTP59P/viNpyXLs",
-	"7Mc1t61vsgQQXwAfgN5CZVtnDRomKLfgpJctMvr46xJXusKry2vJy+vwJbxUSJXXjrU1UMLVpbALIYWK",
-	"poKt8FhrYvRiidLzA0oWa81LyEAHByfjs5EtQgnJ7V4ryMDjb532qKBk32EGVC2xlSEkb1wwJvba1ND3",
-	"fTAmZw1h1PnWe+vDQ2UNo+HwKJ1rdCWDzOIzBa3byYnOW4eedfJvqT4VZirpUzS6y4A1N8EqxrzZqYBs",
-	"cLYPn7HipPGwUrfoV+hF0tpn8MHyO9sZ9S/rHsL+E+mJA2Esi0WUHG20WdhBvKyieGylboKUzjnr+Qf8",
-	"Xbauwbyy7b7nF9dX4jYZQAadDw5LZlcWxXq9zic+xe4cOC6mDjZCGiUq6z1WLOKbFg3H2gUqeYniXYPI",
-	"4r00so4fxXtkrysSP1mlTS0uiJAofIEMGl1hKEm5HbR+sAYPRFJZFCQX2GxebGwXE+v3xT2KttM5BE0M",
-	"QAYr9JQymeezfBbOsA6NdBpKOMtn+RlkcVRin4s0JlRsx3npi3G8IheW+Hg4b4ZRlJNhXHjbjgMLMa6P",
-	"JbtSUMK1JU7tpt30q1/GQNnBfvi0hW89LqCEb4r9Fin2JsXx/ujvEpxI/KNVmy9gn9DwfUp+YX0bnkBJ",
-	"xhes2wnUTwzF4D0ZjDHNm6QvQP540bycvTqucsBsl5NYSxKhFg0yKkFdVSHRomuaTWjxq+R+qmpjmGLc",
-	"C30Gr2ezv3bYbZSo9iQpxDJt9xpPQsKdN8MCT7aPqfgZH0Nxu7N7HiAOSjz7Airkqr7vXGOluo8cHGXr",
-	"Y7aohCQhRbARqkuZisRKLt7WpXjdzmd0jFEGnTt98Mf4Ph3r0FdhEdWYC6xLcf59fn5+DtmEVNs9NBNM",
-	"Tdc+oD/C9HE6Y/gJt2NvYkvGtf70Gg8t1sS6opDP/xvp7InNdqFUqPKU2l1Kf2OhPS+7/8EyCxj8CeOh",
-	"ewmocAsaaSxhZY0ioVmwtb+Gv2npBCHFSiu0+xjaMNYnUBwkHsaecPgxvt5B+LVu0D4Dind0QuJQZmMr",
-	"2Qga7vCDfy7zl2/CRZ7Py+/enJ0V0uliNYf+rv8jAAD//1WRSfJiCwAA",
+	"H4sIAAAAAAAC/+x9W3PbONLoX0HpnKrZU4eR5Nwm8dPxbLIzqZPM5IuztQ/7TakgsiViTQIcAJSiTfm/",
+	"f4UbCZKgSNpyLrN6sSURBBqNvqHR3fg8i1leMApUitnl51mBOc5BAtffrjLg8j2W6Xv1s/olARFzUkjC",
+	"6OxyJlNAWLVBJJlFM6J+K7BMZ9GM4hxmlzP9dBbNOPxREg7J7FLyEqKZiFPIsepRHgrVUEhO6HZ2exvN",
+	"XsGOxPDm1ZGB37xCbIMwSnRTJBnisCVCAkcpYC7XgCXaE5mGoTKvrUgyEbK/cZb/Vwn80AMWo9kBERpn",
+	"ZQIowRIjAVQiLBHjCG8UeDIlAkmSg4PsD9VfDdqGs3zmQ7FhPMdSAY0lPLJvdkH7mbOyOIIxi6mtaobU",
+	"IEim7mvMsjKnCqOmlZjHYhfGnH5hItbegeQkPgKb6tusp4QMcpD8gHL90hy93l6iuChXEvIiDJJpORUm",
+	"lkA2jK5cNYscWjRFsVIibB4gQn2MoVIASmCDy0z2gKremgjpr0ySDYmxAmwER1CvucHe08dhYPyWE2H6",
+	"wEoJA3KBqza9ckE/nTpqmcEwBqiVSLzMwCBAZGz/qCwyhhPRA02ZTQXm+u1vI1ZDANd0lMEOMsTW/4JY",
+	"kp2Di0h4hB/ljMo0O4QhExmbChjJgMZDqyNMq971sc8njv2R3UE6rmHDOAwKRsnuIhb/AeuUsZsRS7U3",
+	"Lc3SsEI8KvAWeBg7tu0k7NyqxqJgVIDWrT/h5GcsYY8P6lvMqAQq1UdcFJlly8W/hILxs9dtwVkBXBLT",
+	"SS624ZWo4fqnbvR7NJNEZqpVPfAHC0+NOUOhBtommj6mgFgpi1KimJVZQn9QK4c44DiFROk3DupdSFBl",
+	"FcxuIzXaB/ijBCG//DTtwFOnyc1raI8FInSHM5KombzmnPEvPAk95hT4r4HvgCMDq9Eef2MlTb4w3G7Y",
+	"KaAbs08pMLTRIKsmBigNx9+1BP9IcnhFFBTr0rwYFG8FB5woxjZyXwsWESHYAT+gpOR66ogIxEGWnEKC",
+	"sFD2B8mhfmzmaiTCs/xiKWZRCzcxK6kMg0DLfA28BkHUOCBUgpItt9EsJUKybVAwxWVeZlipC6SHEV5f",
+	"SKZYIsnYjTIucyYk0hqMSMhFdwkrMLsAZNAdeV3GNyBRWRTA0VqthcHBk6VQjP5/39DNLBogCA2OGff3",
+	"zuJXP2DO8UF9z/GnAJFFs5zQ4O/Fs2X495d9vz/r+f1l8Hchk1UCu2HCN1M0gJppGOAMKGZgM0zdqb/s",
+	"HtP0EHiQdQjdMMfTONZrCzkmmQK0LArG5f+DTzgvMpjHLK8119X7N+jaNJhFs5KrF1Ipi8vFYr/fz713",
+	"FrafWVfGENUGYZqgmHEOsUT6lxyoNIzDNloJ/C0DkOgdpnirHyKzExDorywhdIuuhAAh1JNZNMtIDFRo",
+	"arSw/sooNIAUl4uFwBvIDo8OrNQTu63R1xnNwukGNaJxFs12wIWZycV8OV+qPlgBFBdkdjl7Ml/On6gV",
+	"wzLVvLPQqkx/3EKA1z9oAWIUnogQhT0IiTaECzUrxYUaJ2+S2eXsZ5BXpreosdP+Z9BU4n7Paq+hTSQh",
+	"seyzkdyzIzbaiJE2zG5TrVkcGsk+OtFAZhPVM5S/We8f7/eWefV4ubyHzqvXvEei4viGsn0GyRaSFZZj",
+	"LdKo+eL6EBQ+WG8SVoLQOCCd9ylQzV4xowmxigpzbXkxlLJMIWocMDVqQ2CAiHGGZTXBHjDMdksZS9UL",
+	"emW3TPF4Sf0Zj4ZsQ/hExPZMIsNryMySJQZbOHvfWMoAVrqegBVJIucN0D6QSHOHFoICdsCJPMwCeo6D",
+	"YNlu4lRUz2GtZHZljnCagJJEOMFrCMht8gTKsYxTtRrVekWISNFwFgiEOaAUsgStcXyD9inJ1K4MOOgn",
+	"mB58A6MDW1ujG1nUgbIAqmR/pCSkgkhvHAyOQqjY4ayEsIGlH7kZK5T9INCmpLHmCGUdpYAyLCQC1dI6",
+	"RJxtZ2ZLKKKYMgGKlUSDOFm5zrzFMdZcR/sbD6KRhQ05ZQWxpb4WS3s6/8rtk5roaw1jxZH3XqVJKgs7",
+	"YFGbFqr3p0Ya/m8Om9nl7H8taqfvopKaC2+rdhvNno15xe4z9NhWUS4+6/+3C4/vtfxkIiBFrupGCDua",
+	"sBS6T0mcIiFZIRCRTroowmjr1fdMWHTov16nXUUbmlDdZNFyeRu9opHyE0vus1UPsew+ZWoPkmKaZA32",
+	"HDSv14cGFdUTrlaw63G4p0o8a7yzxjtrvLPGO43GC7vefM6pfYh30F5Pl0+HX6mcY3dSd2b2YvG5QsPt",
+	"4CbRHVgqJlLkGJecA5Wo4CCsv72zYzR+MWH+vUkma7TuceqJN0vHZao5MQw9MadhoScVOjpjKfJeVUe8",
+	"R2S0MI5QzQ4cYiA7RVHe6XB1BlptPseJqiEdZVcYqARuneGON0YOEBYjJVWsQSPEaEYoREjpnQixzUZ9",
+	"NVIlZjs16KD14ADqMOd7h3j9gjlRnyTKWwP5EqI+/PT79Ra7ad4asj1m3poWX4/bF5iyHGeWMo/xvSIC",
+	"3xGt+b8mxS0urEd3DxxQSUtR4iw7oA3mbTr9QaA1FqDWfISwuKogPIHUOO7ScSPVjirgBERkJ75SE1dU",
+	"Wk17RRSL7HDW4/epzvUnOJn2mFOlYw1Qwvr7DWyHCMWcSBLjDGnItUVZ/wKiBxDP2jkKygBGW8EjI95o",
+	"HKee2sPl026PkysBqU/0VmMk7SbD260VeEoS8fGmL3wq9Dhhq8eRO8oBU1QNa8ZAmHMl2uejD3Iu8qUI",
+	"AWHJrSt3R5BvpzMBVA7sITT+WSncRNR2QhhX+Eg94cjyso8LGK/oe6KlWUOnG80nH5N1Bvv3SsSMB4ZL",
+	"2R7lmB6UlqQJ5okWdNZkra1ehZ5KFHoScKr9WksVj6vtakUNincI8uiznoZv0BrpMuzEqTgupOgqST2s",
+	"8WpZ+10Zx4uG2RZ2B31wUXwdSw3XdlrX99PSer9UA53KVj6FA8iTCXewpNzbHu1U0zzq83ka5nA/yEHh",
+	"IgMJCRJlHIMQmzLLDl+RUgyTDp+5IVHmOeYHtSc2qt686RviKRaIQ8G4YeAhi8meFn57uywPJ31nUrtt",
+	"k7h6JGI0LmxB6KPgcNgCoTEHLALiXHcNXGgTK9ILIZnEGXKvIBzrNkpBbYwvBOQoT0Q0uyG0z+clQfTM",
+	"KGd2C0glchJ9xFimy0leLBvEMKJzG9YwoqWxQQNTLimR4fAEJx8MKV8bFhlUT46+QsrJMsUI1WT5z/Bl",
+	"paEeVIhEfUcLSeLFE1tqbiqTOXq9g+ohykshjecQYTeTerdqj6u9jZjb0Q4qpFMLlZOoI8ve/fKkl/Qm",
+	"arKaVlvbx8I4pyMf8UY3VThXbxqb37gJ5FFDdqoxqGdY23kBHWt5SONqkIUcSjvvi4fU0N+L+WcFzOKz",
+	"+XA7xmniuNYEUxO6zZyIGa/K37n2p3CADLzUTnX47twCw1JhMvNP4k7HN9eScUiOc180s+bf2RQ6m0KT",
+	"TKG23La/RyEJ3jKCjtlA76zxYzv5eqLWudQXsHOJfUOStjJq3Lvm1ALFKaZbEBFiWXI0sLElfN1JwmsD",
+	"wReRvt+WKK1x37dfO+pV9bA/IYiAs/zER0fRTLKHPY2yKY86wadpfTWIaND8sghv8m6TDo9xr2uJbD9f",
+	"j33Nycmw98NuUUy4PiRI4Vv7ZiMkSiLxOgOtV+IUc6ldtD/ppnUio87CMnEBOi0r0U9UGmGZZZWKGOL1",
+	"awPvQxwx2QMGAsIk+aqZqyMl5473vPNDx0gTEtl6EiIE+TcYB7jzfdsjhWd5hC7S3qBsKKYO/10JOid0",
+	"RloG1TlL51HByHGJOcpYqxzHoiYQeyoJlleChpwOKet2rn92QTfV6xNN3/aRkibfAngMVOKtpmS8A64+",
+	"eufErWidOUJX1NG+Jcm9jlbypqxPkJNSNZCIMgruhAwRgZZKXoN9iTKpI6TU/lK9QITm+khROFFH1I2T",
+	"WzeeFRx1TlPXcFO9KNnjKP34HtygPaoSaQzOPCFupMt7RRvhqCsIh5cYtXUfB4VdIC1DDdojRIX96sud",
+	"gdw4J4H0KJETCL7as5QfMjvN9I8prp+c/Ld647vyCgiJhw1U0x6ZtiP0kW13enWEM+G0UDuXEAufVyOU",
+	"kywj9puO98AUvbn+7dGL58uL+i1Cq8994Qe0zIGTeOW36yiPNWMZYHr6RJjdduXTemeF7n0wHGcsvlkl",
+	"nGzkqijy8AHxBgvpH+rol5S1KUAqYaXtZB1kSSjKSczdOighi8znOUJvYSORFl9UkswP9ZQoAzXGxdIX",
+	"ptqCVTLS/xm5g/+RO3UzQQNr3+x4d3JKBKc2idULs9API0Rha1JD9YZhDSmhE2IgHl3Mn4lweLTE5Hjw",
+	"8IgZdxSoFEgUEKvIW2XGeXNV7xqYFAiGUdZYSuCHlSmaoKQtRgljfA1aO+22uhgIcCxLDva5ACoYD8Uj",
+	"12xzZFJNoieCKS4N1Apo86+3ENU4JjbdGdI5oaVAgmytrfj+45N3jy/mL569+PHpjz8+f34dtJFEd2xf",
+	"nowjPCpCpVaCwb+EyudPA1ZR21+uXsrVH4ciT2O9sgjoUoCOtrL+e0OwHZmmyF1yFWny/+EACVqb8CxX",
+	"GUZ9rih5QyBLIoNgTzatEi9r1jTS3owN+QQ2gbklzaLe1+cmbbdDTBtMspLDigdDNNVTSGynwjBhbeRF",
+	"qIsH19QdUCMOoszkKmYJzBGovcX82bjVFjewh2RloonEBCNZsZzh0X3KBCDr4DURNyVXQrKOvPGlkApZ",
+	"rPIyNmRbckhQjj+RvMyDFrZMOSu3aVEG5GDb/K2aKon+7qeFGIW+9UGCsEsMyTjE9dGAyQPNftv0Gg9G",
+	"aYpFT+K2UsVBg5LYIw3P1D82O23SuykaiMO6+O/69zbdGTp6+WL+8uXLMRhphy21LIBq+KCxquhoxDGx",
+	"ojciJIm1tfptB6kcO18ugKOkM6URJ8InM1HvcR7cxbcVmkalxjgHjiPEZFrPUtGKUOcWUGiDjHDE9hQV",
+	"+KAoxEhZp6sFwklivGldvR5Zla2DHhPka3TVNi1zkhB5QIQKCTjpVN5ocXpPkaN/V9rDtUQ7kgBTQkX3",
+	"MEYJKhOOFJWuGhKtnopVX6vxaijGDUpB7hm/WZknwTFNC69nVAqn6/ZkQyKUyXC+Va1mul0vEfFxZvgN",
+	"EkgsLeyJgDqVpTLsrG5EutPQhDhIfliN8uGYKHkPhr2O4pKcQBLse3pIwrFNTf+CElt1RTIHGTYrPGw8",
+	"1VGm/tid+h9WhH7HEX2bDECO29jrRlVgzT5lymOtXkc4Y3RrbDyMBOPamx2hAm8J1QmXnO098WsjeYxH",
+	"ixgElKoZoXXkQIGFsE7yegeSrEMeBV1MpEdIt095dfFEyTSUumfO9gKtD5fIy6R0/qOOCVpbYlGl8RlH",
+	"zUSnOXpl6gpqN7ifVRP0OJvqLhMc3FjEalD1Y3MoLOKeQRhPgE8bpT6CZvuWP199u1gul83Rny17Bs9I",
+	"ToJT9JjvOFM7EMQNKfpmaLbs00bxc2L0GC4dJgWcyfRo+RbT5A5lVaqierZOpRvTVesMDeae/YlSWoZ8",
+	"VSEXO445Ey5RyDw4QbaDXYghzdJYLyv5ImSloV4gsxNgVLvnt2QHNFxGzFDOcM63985IwADHaZt4u0nf",
+	"bD8QmTx9ZSpBvSc0YfuTrcr0RNUauy3fnf79EKEEthxrA6mk9ke1ipSt1AFrCJRa6k/YqRPq4SNIB6PT",
+	"Y/1IIN+z6Q0wPSOpP6HXHReNq5Q3YqJ9W+CyCNHOibfEoazWSnZbyKLAxtlb9Bolv7cqmNm8V7YPHXbp",
+	"+LEhLGq10yiAsCGZDkSzpVa1EbWdhFede+eQu/HsJ/EguBWDiDTINviw8qfpkKgtuGP+CN2q5Y74IsVy",
+	"tLQRi8/6/+1Ua9mePRkLVpu4Tp13LFldGFzov3dzOrQqi39DSrvSkl9NYzusdxmpX5t8E/7GDgrvyPw+",
+	"Hk4sCRxuR0iEJufXtH6M83WrDuc/+OZY6wsVBK/+D2e6vSX24NM2bOejVG6YcC7KzyB1pXuh/941DaVV",
+	"LP+LJ7Y1UBLgKBcL3Wg32+Jya44HdUiJrbCwVY4b7WJMdPyiDdLutHP7KhOoXZ0XH0yQ9pGQ5yYQGeZb",
+	"EDbaGmEbG4xyfEAp3o2Mv7Yh0s2eRY6z7N5dD8ZUNwfVtzFAJkgpIuNAjVDyUx4MgWnFXpvVv1sWmk+9",
+	"x3j6Q80crqMTMmkjcyV4iOXGN/GvRYZjqMM0R7Dr+zLArnfMX2kz7R2SV06Wa3Zm4Adj4Pvzqc9+eiFs",
+	"p7+HWfibSmWbztqNAnBjEiP8nXLj5eFaz782xppQ8rkxjnGCV7WfS3Gk+HMp7l6UuTloVZu5vusiNGj9",
+	"9LSj2jtc7C0xoZHdsy9VErpDOL1ZHRLyQvY43ZQ4khOLIyaQkR3wyW8drXS5AyqDT3qrSgq5AncFRtur",
+	"dKhrD9r5IxMME4KMwie5ss0mTcmeModljFo/tGbJwYh/IlDBhC0Rqj1BFaUGvJelhGO3m+kGuhefCtQY",
+	"G8Yjd0bduEemr7JbKY5UiKxW2hzGKFf0K9Vxk0k4pjqIUQ1nic0U48fUXMlSdRMCwsE3eLOBdm8ZOqlR",
+	"H9Vixo09a1B18xKQCupBw6/JXk3zz+/oqP3XaPh1VMris//1dlGtRn/Rm2tQ0cFN0sJbTKg7/txwECkS",
+	"0FjyYJRJAwH+lw8VGFPtyPD9Y7e/n1r3P/jOWzPxKJXvMX0w3PyDe3JC9VJD16dXFFC5vt2Cd5/qSzI6",
+	"aUva8uWZc9pceV1E7hIGzMGTlAtckMXusa3ljUqaAEdkuB61Gr9d5NWO9Ju+NUorHXNDSWdmScJDgdFC",
+	"Xhaszr25fvfxvY0/DJaZzjK2XxUZJlTCp8AC6zgjl7/y8e11XUrO6xklDIS624ptNsDR9cerDx8/vr1W",
+	"RrS72k+/oqeidZzwQpTawfjRbG03Ti2+gE9yodg4wxKq8Afd9khSZ0AfCrFnPKAQKai5OKdoqFNRGvU3",
+	"EjbbPEICjCL88Prq1bvX1XMTOdafPTq+inIpgDuvRCtB8+3Vm18RLmWqNG8pIDFLKGCYPjWN+Tk3/iVb",
+	"ai09Kq3ydrvCzDxTjKKoyd0nZeSFfmYgUgL6MDe/z03ZZ8tvc1f6Wad86V+qGuKTik33mGn62Ad4AHwD",
+	"qn3erriiIflBIFO9uTmvekb9F0wRseKwhcD+2Yxht7jKP8xhW2aYI/hUcBCiUnZCejFAGo4gQ4VJw1if",
+	"a8jU0ughIwTbtns+kJh3nGoa9WH8/bDBYmhdwtF6vvBWK28kYTTGAtNNvIWNAnnOWiENmlhWxTRtK/3q",
+	"caeaee0eOnfxWf+/NXjJIGRsv9K/e9d32u1dU/maVhZm22KaOdO6uvQ7s2Oi4xnhx5FXr/YDYe7eNy6d",
+	"zZuzeXM2b87mzdm8OZs3XfOm57ZeZ/s8fBpQ6Mjvr9r11Tjwa2jh6horoImoidEL0CLuC3ATck0bmkx1",
+	"vT5UKO0cGJ5cpZ/iwO+szc/a/KzNz9q8ut35rLb/NGpbP21r5+88KqDpp1i4CppHz2tynIAKRfEvrXR5",
+	"6zYNrJSAhOSYbFOJ8B4fIkUJcQqqvoeibKGvWUdcNQge6Hja/aMC6j/J3aFaPx619j9jCXt8qFazzIYP",
+	"enxhYu+z67pLdEenPecpM7hPrKMTwCu8kcB7DIeWrDbCsnm9KkqxNh3WALR57V4Vg5ExFzV98SzvubeH",
+	"h2OPTKTuXx4/Tf8P+u9yuXwSo5fPdM9PlnmEipfP/tJIgrxw7QA9y+foukdv27sOxYTbMEXMQqLPhuEx",
+	"3i+N9Zs2fqLLH7YDklR9mGIl7mpGpIlCly2cdnnPhoO2ZBSasBCQ+Kmw9f7BLIy73idC9s6fcZsuvXAO",
+	"N95M20axov9ht3LZrZirXhy+mhiZV+8msdWri8/q31G/8gfI2c7flJUZzNEbKRrcYHYW3rWcQbeznpQR",
+	"FROlcJn9+X3Ox2Tow2Bt+YAhqWc5e5azX1jOHpOS36aDK8Ty78sTs/yXCEA/c3sPt5/ZusnWIzha0fD3",
+	"vh32jKtFSoRk5pKNKbHy9R3k5voWX2gMh85XIuQXO/o3ZTx4cxus7z/yAnxdkOn4xvBYPLfxoCWhirC9",
+	"N0rXoY66AdKyDxnPoZtfVJeJtg5ori6JoMy8OaHodbuf+n4+Fd9sKtG38/7vlZhvMdLhTIXo4cs65axe",
+	"lFYXryvsjLiSoCaU7g7J0vbR4BslC/1evkysrCAZ0HjYieLaDTP0tetxQhqM672+UvpI8Rz37Evlg/go",
+	"6mHZuyR7uHfWh7ChQhMxqb+HPEg/e96HPe8csOgxOnWd4Gmr2SPJqwQSHOtaxEyjk4y5fIUkzdP3Gqia",
+	"2BpUWU2pkfbhQPPr9hsGGZSRFSM1BaR9/agXybX5IoZRXx1QC0TwbL/JTGuQewCKKhzrtJ0ay133vyc1",
+	"T7MROrV4OcuRb0yOtPdpdTE92jKpRl897UmH4xKhy/xH90EXJ6Djs2o9s8R/sGoNh8RR2Dvb+Yu5DOx4",
+	"YvHZfjp6KvNao1BXZtWNlXCKzJH4DRQSCZOza10PiGinWsaE7DmacXrS/p/sLbDv/VnPaCpqOLIxe0jU",
+	"Lc+S/izpz5L+9JK+IeUf3iuUsZFhNQK4du6ZK3xMpQV9W5HywhMpUFxyru8FkBITmrsrAkANh9ZlooYI",
+	"CSsFwYAHyXrLwK//aZYWYWn8SPZyvapqgzGNQ14lffxQi6ZR1vOJ/UwW6b1p4RlT976oYkj9xezdU40T",
+	"iflWV1jPVKHQO5fZfZI+eZ6HiynWq3rPCq0v5y8uHo8rhLQuOdX38pz29qrG7WM+eSIi0BoUVYlCkW81",
+	"IV0F5SKN0PNU0/Tjp+kcXehWiUDwCccyO9jbJHVHLVQEL7Eat7p3XsuL9HHPUuoprwykKw45JvqcrO+u",
+	"RH2Z5aaLrAw2sn1rGdHHpeasVEGu8ThuscP3HDeu6rR0pYvBKNvMiYXxlyn3aP3eknlf46y0Fq36rjox",
+	"pewMu4kQlitOxI3qec0B6xOTQBdGZITB695mWnCWE1FdSOzR42vD1COvtApdPA00Od0Kmx7C0+Isy/R5",
+	"lRulFpzV0XT7MuBX+GAiyaxUtoJymUTo4vmLdGRGlMF1BV3gtNmrwtO8PLsSu1FXMXhSpJetG2LUN0Pe",
+	"/nZtRhz05mas8aZS2m9/O+7FDRsL4u5GygLLR4qsx5xZ95gqtoqgWkzHGIpJhLUS7d1HDRknGUN/lCS+",
+	"yQ59tsuV/KDAOlswZwvmbMGcLZizBXO2YM4WzPdhwRjVfRc7RiltbY3c3Z75LDI2Ks2hz5zp85tnTFxn",
+	"bLrT9+1vf15f+QP6ru6P6+hPbiyercGzNXi2Bs/W4NkaPFuDX8QaHG3HfVMpWKPtvPelPKGRd4oQxO9O",
+	"3nwdYfGt8fIINm4y2HeeA2Xr5I888LatVZJdzMHmk3cKG3X2BP9wg5zUhvZB73G6Tq8b1K0YZG/eZptN",
+	"RihE7jsHzUr6NtQ6G3EOn9wl8l+hbqB/555MOYiUZUlPmmcIaDNvTO1DJPENmFROzePY5Ke4KwaD983z",
+	"bOSVC6plqAqepZVB/0W1+k0fhn396EmMa3M/jll8tp/GeSwq1lFFGdzxSvPGizXETJn6pnBZyJHhILf/",
+	"J+s6+9537NQYbTnUF5R0LIUHwuIprIY/q8ASWl9056UrxqkZka3TMT5PGG/UL++u/vro+perx8+eB2XO",
+	"tyL1muOlUhYKbeq/0CUf2zfrtAo9jiroWKGyKzK/Z0tEkwjfhQ+KMxbjrC4zqbGt0Xu5WFw8/nG+nC/n",
+	"F5cvli+WplTmxez299v/GQCJgvuY89UAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
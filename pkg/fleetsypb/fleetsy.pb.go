@@ -0,0 +1,885 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: fleetsy.proto
+
+// gRPC mirror of the REST API described in openapi.json.
+//
+// The generated code lives in pkg/fleetsypb and is produced with protoc-gen-go
+// and protoc-gen-go-grpc using paths=source_relative style output:
+//
+//	protoc -I internal/api --go_out=pkg/fleetsypb --go_opt=paths=source_relative \
+//	  --go-grpc_out=pkg/fleetsypb --go-grpc_opt=paths=source_relative fleetsy.proto
+
+package fleetsypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PostHeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	SentAt        *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PostHeartbeatRequest) Reset() {
+	*x = PostHeartbeatRequest{}
+	mi := &file_fleetsy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostHeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostHeartbeatRequest) ProtoMessage() {}
+
+func (x *PostHeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostHeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*PostHeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PostHeartbeatRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *PostHeartbeatRequest) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+type PostStatsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	SentAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	// the number of nanoseconds it took to upload a video
+	UploadTime int64 `protobuf:"varint,3,opt,name=upload_time,json=uploadTime,proto3" json:"upload_time,omitempty"`
+	// the size of the uploaded video in bytes
+	BytesUploaded *int64 `protobuf:"varint,4,opt,name=bytes_uploaded,json=bytesUploaded,proto3,oneof" json:"bytes_uploaded,omitempty"`
+	// the number of nanoseconds of video in the upload
+	ClipDuration *int64 `protobuf:"varint,5,opt,name=clip_duration,json=clipDuration,proto3,oneof" json:"clip_duration,omitempty"`
+	// 0 if the upload succeeded, otherwise a device specific failure code
+	ResultCode *int32 `protobuf:"varint,6,opt,name=result_code,json=resultCode,proto3,oneof" json:"result_code,omitempty"`
+	// the number of times the upload was retried
+	RetryCount int32 `protobuf:"varint,7,opt,name=retry_count,json=retryCount,proto3" json:"retry_count,omitempty"`
+	// the network the upload used. Eg: wifi, lte
+	NetworkType string `protobuf:"bytes,8,opt,name=network_type,json=networkType,proto3" json:"network_type,omitempty"`
+	// what's reported on top of the fields above depends on the device's model, cameras don't
+	// report anything extra.  Sensors only report their readings and leave the upload fields unset.
+	//
+	// Types that are valid to be assigned to Details:
+	//
+	//	*PostStatsRequest_Doorbell
+	//	*PostStatsRequest_Sensor
+	Details       isPostStatsRequest_Details `protobuf_oneof:"details"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PostStatsRequest) Reset() {
+	*x = PostStatsRequest{}
+	mi := &file_fleetsy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostStatsRequest) ProtoMessage() {}
+
+func (x *PostStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostStatsRequest.ProtoReflect.Descriptor instead.
+func (*PostStatsRequest) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PostStatsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *PostStatsRequest) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+func (x *PostStatsRequest) GetUploadTime() int64 {
+	if x != nil {
+		return x.UploadTime
+	}
+	return 0
+}
+
+func (x *PostStatsRequest) GetBytesUploaded() int64 {
+	if x != nil && x.BytesUploaded != nil {
+		return *x.BytesUploaded
+	}
+	return 0
+}
+
+func (x *PostStatsRequest) GetClipDuration() int64 {
+	if x != nil && x.ClipDuration != nil {
+		return *x.ClipDuration
+	}
+	return 0
+}
+
+func (x *PostStatsRequest) GetResultCode() int32 {
+	if x != nil && x.ResultCode != nil {
+		return *x.ResultCode
+	}
+	return 0
+}
+
+func (x *PostStatsRequest) GetRetryCount() int32 {
+	if x != nil {
+		return x.RetryCount
+	}
+	return 0
+}
+
+func (x *PostStatsRequest) GetNetworkType() string {
+	if x != nil {
+		return x.NetworkType
+	}
+	return ""
+}
+
+func (x *PostStatsRequest) GetDetails() isPostStatsRequest_Details {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *PostStatsRequest) GetDoorbell() *DoorbellStats {
+	if x != nil {
+		if x, ok := x.Details.(*PostStatsRequest_Doorbell); ok {
+			return x.Doorbell
+		}
+	}
+	return nil
+}
+
+func (x *PostStatsRequest) GetSensor() *SensorStats {
+	if x != nil {
+		if x, ok := x.Details.(*PostStatsRequest_Sensor); ok {
+			return x.Sensor
+		}
+	}
+	return nil
+}
+
+type isPostStatsRequest_Details interface {
+	isPostStatsRequest_Details()
+}
+
+type PostStatsRequest_Doorbell struct {
+	Doorbell *DoorbellStats `protobuf:"bytes,9,opt,name=doorbell,proto3,oneof"`
+}
+
+type PostStatsRequest_Sensor struct {
+	Sensor *SensorStats `protobuf:"bytes,10,opt,name=sensor,proto3,oneof"`
+}
+
+func (*PostStatsRequest_Doorbell) isPostStatsRequest_Details() {}
+
+func (*PostStatsRequest_Sensor) isPostStatsRequest_Details() {}
+
+// the extra stats a doorbell reports with each upload
+type DoorbellStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// what triggered the upload, ring or motion
+	Event string `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// battery level as a percentage
+	BatteryLevel  *float64 `protobuf:"fixed64,2,opt,name=battery_level,json=batteryLevel,proto3,oneof" json:"battery_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DoorbellStats) Reset() {
+	*x = DoorbellStats{}
+	mi := &file_fleetsy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DoorbellStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DoorbellStats) ProtoMessage() {}
+
+func (x *DoorbellStats) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DoorbellStats.ProtoReflect.Descriptor instead.
+func (*DoorbellStats) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DoorbellStats) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *DoorbellStats) GetBatteryLevel() float64 {
+	if x != nil && x.BatteryLevel != nil {
+		return *x.BatteryLevel
+	}
+	return 0
+}
+
+// a sensor's readings
+type SensorStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// required, in celsius
+	Temperature *float64 `protobuf:"fixed64,1,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	// relative humidity percentage
+	Humidity      *float64 `protobuf:"fixed64,2,opt,name=humidity,proto3,oneof" json:"humidity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SensorStats) Reset() {
+	*x = SensorStats{}
+	mi := &file_fleetsy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorStats) ProtoMessage() {}
+
+func (x *SensorStats) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorStats.ProtoReflect.Descriptor instead.
+func (*SensorStats) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SensorStats) GetTemperature() float64 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+func (x *SensorStats) GetHumidity() float64 {
+	if x != nil && x.Humidity != nil {
+		return *x.Humidity
+	}
+	return 0
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_fleetsy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetStatsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type GetStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Uptime as a percentage. eg: 98.999
+	Uptime float32 `protobuf:"fixed32,1,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	// returned as a time duration string. Eg: 5m10s
+	AvgUploadTime string `protobuf:"bytes,2,opt,name=avg_upload_time,json=avgUploadTime,proto3" json:"avg_upload_time,omitempty"`
+	// how far the device clock is ahead of the server clock, negative when behind. Eg: -1.5s
+	ClockOffset string `protobuf:"bytes,3,opt,name=clock_offset,json=clockOffset,proto3" json:"clock_offset,omitempty"`
+	// how fast the device clock offset is changing, in microseconds per second
+	ClockDriftPpm *float64 `protobuf:"fixed64,4,opt,name=clock_drift_ppm,json=clockDriftPpm,proto3,oneof" json:"clock_drift_ppm,omitempty"`
+	// heartbeats and stats whose sent_at was further from the server clock than the configured maximum
+	SkewedRecords int64 `protobuf:"varint,5,opt,name=skewed_records,json=skewedRecords,proto3" json:"skewed_records,omitempty"`
+	// average upload throughput in MB/s, only set when uploads reported bytes_uploaded
+	Throughput *float64 `protobuf:"fixed64,6,opt,name=throughput,proto3,oneof" json:"throughput,omitempty"`
+	// failed uploads as a percentage, only set when uploads reported result_code
+	FailureRate *float64 `protobuf:"fixed64,7,opt,name=failure_rate,json=failureRate,proto3,oneof" json:"failure_rate,omitempty"`
+	// stats specific to the device type, eg rings for a doorbell
+	Details map[string]float64 `protobuf:"bytes,8,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	// percentiles of upload time, only set when uploads were reported
+	UploadTimeDistribution *UploadTimeDistribution `protobuf:"bytes,9,opt,name=upload_time_distribution,json=uploadTimeDistribution,proto3" json:"upload_time_distribution,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_fleetsy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetStatsResponse) GetUptime() float32 {
+	if x != nil {
+		return x.Uptime
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetAvgUploadTime() string {
+	if x != nil {
+		return x.AvgUploadTime
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetClockOffset() string {
+	if x != nil {
+		return x.ClockOffset
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetClockDriftPpm() float64 {
+	if x != nil && x.ClockDriftPpm != nil {
+		return *x.ClockDriftPpm
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetSkewedRecords() int64 {
+	if x != nil {
+		return x.SkewedRecords
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetThroughput() float64 {
+	if x != nil && x.Throughput != nil {
+		return *x.Throughput
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetFailureRate() float64 {
+	if x != nil && x.FailureRate != nil {
+		return *x.FailureRate
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetDetails() map[string]float64 {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetUploadTimeDistribution() *UploadTimeDistribution {
+	if x != nil {
+		return x.UploadTimeDistribution
+	}
+	return nil
+}
+
+// the spread of upload times, every duration is a time duration string. Eg: 5m10s
+type UploadTimeDistribution struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Count  uint64                 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Min    string                 `protobuf:"bytes,2,opt,name=min,proto3" json:"min,omitempty"`
+	Max    string                 `protobuf:"bytes,3,opt,name=max,proto3" json:"max,omitempty"`
+	P50    string                 `protobuf:"bytes,4,opt,name=p50,proto3" json:"p50,omitempty"`
+	P90    string                 `protobuf:"bytes,5,opt,name=p90,proto3" json:"p90,omitempty"`
+	P95    string                 `protobuf:"bytes,6,opt,name=p95,proto3" json:"p95,omitempty"`
+	P99    string                 `protobuf:"bytes,7,opt,name=p99,proto3" json:"p99,omitempty"`
+	StdDev string                 `protobuf:"bytes,8,opt,name=std_dev,json=stdDev,proto3" json:"std_dev,omitempty"`
+	// cumulative counts of uploads that took at most le
+	Histogram     []*HistogramBucket `protobuf:"bytes,9,rep,name=histogram,proto3" json:"histogram,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadTimeDistribution) Reset() {
+	*x = UploadTimeDistribution{}
+	mi := &file_fleetsy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadTimeDistribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadTimeDistribution) ProtoMessage() {}
+
+func (x *UploadTimeDistribution) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadTimeDistribution.ProtoReflect.Descriptor instead.
+func (*UploadTimeDistribution) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UploadTimeDistribution) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *UploadTimeDistribution) GetMin() string {
+	if x != nil {
+		return x.Min
+	}
+	return ""
+}
+
+func (x *UploadTimeDistribution) GetMax() string {
+	if x != nil {
+		return x.Max
+	}
+	return ""
+}
+
+func (x *UploadTimeDistribution) GetP50() string {
+	if x != nil {
+		return x.P50
+	}
+	return ""
+}
+
+func (x *UploadTimeDistribution) GetP90() string {
+	if x != nil {
+		return x.P90
+	}
+	return ""
+}
+
+func (x *UploadTimeDistribution) GetP95() string {
+	if x != nil {
+		return x.P95
+	}
+	return ""
+}
+
+func (x *UploadTimeDistribution) GetP99() string {
+	if x != nil {
+		return x.P99
+	}
+	return ""
+}
+
+func (x *UploadTimeDistribution) GetStdDev() string {
+	if x != nil {
+		return x.StdDev
+	}
+	return ""
+}
+
+func (x *UploadTimeDistribution) GetHistogram() []*HistogramBucket {
+	if x != nil {
+		return x.Histogram
+	}
+	return nil
+}
+
+type HistogramBucket struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// bucket upper bound. Eg: 30s or +Inf
+	Le            string `protobuf:"bytes,1,opt,name=le,proto3" json:"le,omitempty"`
+	Count         uint64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistogramBucket) Reset() {
+	*x = HistogramBucket{}
+	mi := &file_fleetsy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistogramBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistogramBucket) ProtoMessage() {}
+
+func (x *HistogramBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistogramBucket.ProtoReflect.Descriptor instead.
+func (*HistogramBucket) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HistogramBucket) GetLe() string {
+	if x != nil {
+		return x.Le
+	}
+	return ""
+}
+
+func (x *HistogramBucket) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type StreamHeartbeatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accepted      int64                  `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected      int64                  `protobuf:"varint,2,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamHeartbeatsResponse) Reset() {
+	*x = StreamHeartbeatsResponse{}
+	mi := &file_fleetsy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamHeartbeatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamHeartbeatsResponse) ProtoMessage() {}
+
+func (x *StreamHeartbeatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fleetsy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamHeartbeatsResponse.ProtoReflect.Descriptor instead.
+func (*StreamHeartbeatsResponse) Descriptor() ([]byte, []int) {
+	return file_fleetsy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamHeartbeatsResponse) GetAccepted() int64 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *StreamHeartbeatsResponse) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+var File_fleetsy_proto protoreflect.FileDescriptor
+
+const file_fleetsy_proto_rawDesc = "" +
+	"\n" +
+	"\rfleetsy.proto\x12\n" +
+	"fleetsy.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"h\n" +
+	"\x14PostHeartbeatRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x123\n" +
+	"\asent_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x06sentAt\"\xf1\x03\n" +
+	"\x10PostStatsRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x123\n" +
+	"\asent_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x06sentAt\x12\x1f\n" +
+	"\vupload_time\x18\x03 \x01(\x03R\n" +
+	"uploadTime\x12*\n" +
+	"\x0ebytes_uploaded\x18\x04 \x01(\x03H\x01R\rbytesUploaded\x88\x01\x01\x12(\n" +
+	"\rclip_duration\x18\x05 \x01(\x03H\x02R\fclipDuration\x88\x01\x01\x12$\n" +
+	"\vresult_code\x18\x06 \x01(\x05H\x03R\n" +
+	"resultCode\x88\x01\x01\x12\x1f\n" +
+	"\vretry_count\x18\a \x01(\x05R\n" +
+	"retryCount\x12!\n" +
+	"\fnetwork_type\x18\b \x01(\tR\vnetworkType\x127\n" +
+	"\bdoorbell\x18\t \x01(\v2\x19.fleetsy.v1.DoorbellStatsH\x00R\bdoorbell\x121\n" +
+	"\x06sensor\x18\n" +
+	" \x01(\v2\x17.fleetsy.v1.SensorStatsH\x00R\x06sensorB\t\n" +
+	"\adetailsB\x11\n" +
+	"\x0f_bytes_uploadedB\x10\n" +
+	"\x0e_clip_durationB\x0e\n" +
+	"\f_result_code\"a\n" +
+	"\rDoorbellStats\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x12(\n" +
+	"\rbattery_level\x18\x02 \x01(\x01H\x00R\fbatteryLevel\x88\x01\x01B\x10\n" +
+	"\x0e_battery_level\"r\n" +
+	"\vSensorStats\x12%\n" +
+	"\vtemperature\x18\x01 \x01(\x01H\x00R\vtemperature\x88\x01\x01\x12\x1f\n" +
+	"\bhumidity\x18\x02 \x01(\x01H\x01R\bhumidity\x88\x01\x01B\x0e\n" +
+	"\f_temperatureB\v\n" +
+	"\t_humidity\".\n" +
+	"\x0fGetStatsRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"\xa9\x04\n" +
+	"\x10GetStatsResponse\x12\x16\n" +
+	"\x06uptime\x18\x01 \x01(\x02R\x06uptime\x12&\n" +
+	"\x0favg_upload_time\x18\x02 \x01(\tR\ravgUploadTime\x12!\n" +
+	"\fclock_offset\x18\x03 \x01(\tR\vclockOffset\x12+\n" +
+	"\x0fclock_drift_ppm\x18\x04 \x01(\x01H\x00R\rclockDriftPpm\x88\x01\x01\x12%\n" +
+	"\x0eskewed_records\x18\x05 \x01(\x03R\rskewedRecords\x12#\n" +
+	"\n" +
+	"throughput\x18\x06 \x01(\x01H\x01R\n" +
+	"throughput\x88\x01\x01\x12&\n" +
+	"\ffailure_rate\x18\a \x01(\x01H\x02R\vfailureRate\x88\x01\x01\x12C\n" +
+	"\adetails\x18\b \x03(\v2).fleetsy.v1.GetStatsResponse.DetailsEntryR\adetails\x12\\\n" +
+	"\x18upload_time_distribution\x18\t \x01(\v2\".fleetsy.v1.UploadTimeDistributionR\x16uploadTimeDistribution\x1a:\n" +
+	"\fDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01B\x12\n" +
+	"\x10_clock_drift_ppmB\r\n" +
+	"\v_throughputB\x0f\n" +
+	"\r_failure_rate\"\xee\x01\n" +
+	"\x16UploadTimeDistribution\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x04R\x05count\x12\x10\n" +
+	"\x03min\x18\x02 \x01(\tR\x03min\x12\x10\n" +
+	"\x03max\x18\x03 \x01(\tR\x03max\x12\x10\n" +
+	"\x03p50\x18\x04 \x01(\tR\x03p50\x12\x10\n" +
+	"\x03p90\x18\x05 \x01(\tR\x03p90\x12\x10\n" +
+	"\x03p95\x18\x06 \x01(\tR\x03p95\x12\x10\n" +
+	"\x03p99\x18\a \x01(\tR\x03p99\x12\x17\n" +
+	"\astd_dev\x18\b \x01(\tR\x06stdDev\x129\n" +
+	"\thistogram\x18\t \x03(\v2\x1b.fleetsy.v1.HistogramBucketR\thistogram\"7\n" +
+	"\x0fHistogramBucket\x12\x0e\n" +
+	"\x02le\x18\x01 \x01(\tR\x02le\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x04R\x05count\"R\n" +
+	"\x18StreamHeartbeatsResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\x03R\baccepted\x12\x1a\n" +
+	"\brejected\x18\x02 \x01(\x03R\brejected2\xbc\x02\n" +
+	"\aFleetsy\x12I\n" +
+	"\rPostHeartbeat\x12 .fleetsy.v1.PostHeartbeatRequest\x1a\x16.google.protobuf.Empty\x12A\n" +
+	"\tPostStats\x12\x1c.fleetsy.v1.PostStatsRequest\x1a\x16.google.protobuf.Empty\x12E\n" +
+	"\bGetStats\x12\x1b.fleetsy.v1.GetStatsRequest\x1a\x1c.fleetsy.v1.GetStatsResponse\x12\\\n" +
+	"\x10StreamHeartbeats\x12 .fleetsy.v1.PostHeartbeatRequest\x1a$.fleetsy.v1.StreamHeartbeatsResponse(\x01B\x17Z\x15fleetsy/pkg/fleetsypbb\x06proto3"
+
+var (
+	file_fleetsy_proto_rawDescOnce sync.Once
+	file_fleetsy_proto_rawDescData []byte
+)
+
+func file_fleetsy_proto_rawDescGZIP() []byte {
+	file_fleetsy_proto_rawDescOnce.Do(func() {
+		file_fleetsy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fleetsy_proto_rawDesc), len(file_fleetsy_proto_rawDesc)))
+	})
+	return file_fleetsy_proto_rawDescData
+}
+
+var file_fleetsy_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_fleetsy_proto_goTypes = []any{
+	(*PostHeartbeatRequest)(nil),     // 0: fleetsy.v1.PostHeartbeatRequest
+	(*PostStatsRequest)(nil),         // 1: fleetsy.v1.PostStatsRequest
+	(*DoorbellStats)(nil),            // 2: fleetsy.v1.DoorbellStats
+	(*SensorStats)(nil),              // 3: fleetsy.v1.SensorStats
+	(*GetStatsRequest)(nil),          // 4: fleetsy.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),         // 5: fleetsy.v1.GetStatsResponse
+	(*UploadTimeDistribution)(nil),   // 6: fleetsy.v1.UploadTimeDistribution
+	(*HistogramBucket)(nil),          // 7: fleetsy.v1.HistogramBucket
+	(*StreamHeartbeatsResponse)(nil), // 8: fleetsy.v1.StreamHeartbeatsResponse
+	nil,                              // 9: fleetsy.v1.GetStatsResponse.DetailsEntry
+	(*timestamppb.Timestamp)(nil),    // 10: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),            // 11: google.protobuf.Empty
+}
+var file_fleetsy_proto_depIdxs = []int32{
+	10, // 0: fleetsy.v1.PostHeartbeatRequest.sent_at:type_name -> google.protobuf.Timestamp
+	10, // 1: fleetsy.v1.PostStatsRequest.sent_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: fleetsy.v1.PostStatsRequest.doorbell:type_name -> fleetsy.v1.DoorbellStats
+	3,  // 3: fleetsy.v1.PostStatsRequest.sensor:type_name -> fleetsy.v1.SensorStats
+	9,  // 4: fleetsy.v1.GetStatsResponse.details:type_name -> fleetsy.v1.GetStatsResponse.DetailsEntry
+	6,  // 5: fleetsy.v1.GetStatsResponse.upload_time_distribution:type_name -> fleetsy.v1.UploadTimeDistribution
+	7,  // 6: fleetsy.v1.UploadTimeDistribution.histogram:type_name -> fleetsy.v1.HistogramBucket
+	0,  // 7: fleetsy.v1.Fleetsy.PostHeartbeat:input_type -> fleetsy.v1.PostHeartbeatRequest
+	1,  // 8: fleetsy.v1.Fleetsy.PostStats:input_type -> fleetsy.v1.PostStatsRequest
+	4,  // 9: fleetsy.v1.Fleetsy.GetStats:input_type -> fleetsy.v1.GetStatsRequest
+	0,  // 10: fleetsy.v1.Fleetsy.StreamHeartbeats:input_type -> fleetsy.v1.PostHeartbeatRequest
+	11, // 11: fleetsy.v1.Fleetsy.PostHeartbeat:output_type -> google.protobuf.Empty
+	11, // 12: fleetsy.v1.Fleetsy.PostStats:output_type -> google.protobuf.Empty
+	5,  // 13: fleetsy.v1.Fleetsy.GetStats:output_type -> fleetsy.v1.GetStatsResponse
+	8,  // 14: fleetsy.v1.Fleetsy.StreamHeartbeats:output_type -> fleetsy.v1.StreamHeartbeatsResponse
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_fleetsy_proto_init() }
+func file_fleetsy_proto_init() {
+	if File_fleetsy_proto != nil {
+		return
+	}
+	file_fleetsy_proto_msgTypes[1].OneofWrappers = []any{
+		(*PostStatsRequest_Doorbell)(nil),
+		(*PostStatsRequest_Sensor)(nil),
+	}
+	file_fleetsy_proto_msgTypes[2].OneofWrappers = []any{}
+	file_fleetsy_proto_msgTypes[3].OneofWrappers = []any{}
+	file_fleetsy_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fleetsy_proto_rawDesc), len(file_fleetsy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fleetsy_proto_goTypes,
+		DependencyIndexes: file_fleetsy_proto_depIdxs,
+		MessageInfos:      file_fleetsy_proto_msgTypes,
+	}.Build()
+	File_fleetsy_proto = out.File
+	file_fleetsy_proto_goTypes = nil
+	file_fleetsy_proto_depIdxs = nil
+}
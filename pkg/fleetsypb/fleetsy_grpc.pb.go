@@ -0,0 +1,248 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: fleetsy.proto
+
+// gRPC mirror of the REST API described in openapi.json.
+//
+// The generated code lives in pkg/fleetsypb and is produced with protoc-gen-go
+// and protoc-gen-go-grpc using paths=source_relative style output:
+//
+//	protoc -I internal/api --go_out=pkg/fleetsypb --go_opt=paths=source_relative \
+//	  --go-grpc_out=pkg/fleetsypb --go-grpc_opt=paths=source_relative fleetsy.proto
+
+package fleetsypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Fleetsy_PostHeartbeat_FullMethodName    = "/fleetsy.v1.Fleetsy/PostHeartbeat"
+	Fleetsy_PostStats_FullMethodName        = "/fleetsy.v1.Fleetsy/PostStats"
+	Fleetsy_GetStats_FullMethodName         = "/fleetsy.v1.Fleetsy/GetStats"
+	Fleetsy_StreamHeartbeats_FullMethodName = "/fleetsy.v1.Fleetsy/StreamHeartbeats"
+)
+
+// FleetsyClient is the client API for Fleetsy service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FleetsyClient interface {
+	// Register a heartbeat from a device
+	PostHeartbeat(ctx context.Context, in *PostHeartbeatRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Add per device statistics
+	PostStats(ctx context.Context, in *PostStatsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Return device stats
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// Register many heartbeats over a single stream, eg from a gateway.
+	// Heartbeats for unknown devices are counted as rejected rather than ending the stream.
+	StreamHeartbeats(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PostHeartbeatRequest, StreamHeartbeatsResponse], error)
+}
+
+type fleetsyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFleetsyClient(cc grpc.ClientConnInterface) FleetsyClient {
+	return &fleetsyClient{cc}
+}
+
+func (c *fleetsyClient) PostHeartbeat(ctx context.Context, in *PostHeartbeatRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Fleetsy_PostHeartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fleetsyClient) PostStats(ctx context.Context, in *PostStatsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Fleetsy_PostStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fleetsyClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, Fleetsy_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fleetsyClient) StreamHeartbeats(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PostHeartbeatRequest, StreamHeartbeatsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Fleetsy_ServiceDesc.Streams[0], Fleetsy_StreamHeartbeats_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PostHeartbeatRequest, StreamHeartbeatsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Fleetsy_StreamHeartbeatsClient = grpc.ClientStreamingClient[PostHeartbeatRequest, StreamHeartbeatsResponse]
+
+// FleetsyServer is the server API for Fleetsy service.
+// All implementations must embed UnimplementedFleetsyServer
+// for forward compatibility.
+type FleetsyServer interface {
+	// Register a heartbeat from a device
+	PostHeartbeat(context.Context, *PostHeartbeatRequest) (*emptypb.Empty, error)
+	// Add per device statistics
+	PostStats(context.Context, *PostStatsRequest) (*emptypb.Empty, error)
+	// Return device stats
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// Register many heartbeats over a single stream, eg from a gateway.
+	// Heartbeats for unknown devices are counted as rejected rather than ending the stream.
+	StreamHeartbeats(grpc.ClientStreamingServer[PostHeartbeatRequest, StreamHeartbeatsResponse]) error
+	mustEmbedUnimplementedFleetsyServer()
+}
+
+// UnimplementedFleetsyServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFleetsyServer struct{}
+
+func (UnimplementedFleetsyServer) PostHeartbeat(context.Context, *PostHeartbeatRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostHeartbeat not implemented")
+}
+func (UnimplementedFleetsyServer) PostStats(context.Context, *PostStatsRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostStats not implemented")
+}
+func (UnimplementedFleetsyServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedFleetsyServer) StreamHeartbeats(grpc.ClientStreamingServer[PostHeartbeatRequest, StreamHeartbeatsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamHeartbeats not implemented")
+}
+func (UnimplementedFleetsyServer) mustEmbedUnimplementedFleetsyServer() {}
+func (UnimplementedFleetsyServer) testEmbeddedByValue()                 {}
+
+// UnsafeFleetsyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FleetsyServer will
+// result in compilation errors.
+type UnsafeFleetsyServer interface {
+	mustEmbedUnimplementedFleetsyServer()
+}
+
+func RegisterFleetsyServer(s grpc.ServiceRegistrar, srv FleetsyServer) {
+	// If the following call pancis, it indicates UnimplementedFleetsyServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Fleetsy_ServiceDesc, srv)
+}
+
+func _Fleetsy_PostHeartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostHeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FleetsyServer).PostHeartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fleetsy_PostHeartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FleetsyServer).PostHeartbeat(ctx, req.(*PostHeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fleetsy_PostStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FleetsyServer).PostStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fleetsy_PostStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FleetsyServer).PostStats(ctx, req.(*PostStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fleetsy_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FleetsyServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fleetsy_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FleetsyServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fleetsy_StreamHeartbeats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FleetsyServer).StreamHeartbeats(&grpc.GenericServerStream[PostHeartbeatRequest, StreamHeartbeatsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Fleetsy_StreamHeartbeatsServer = grpc.ClientStreamingServer[PostHeartbeatRequest, StreamHeartbeatsResponse]
+
+// Fleetsy_ServiceDesc is the grpc.ServiceDesc for Fleetsy service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Fleetsy_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fleetsy.v1.Fleetsy",
+	HandlerType: (*FleetsyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PostHeartbeat",
+			Handler:    _Fleetsy_PostHeartbeat_Handler,
+		},
+		{
+			MethodName: "PostStats",
+			Handler:    _Fleetsy_PostStats_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Fleetsy_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamHeartbeats",
+			Handler:       _Fleetsy_StreamHeartbeats_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "fleetsy.proto",
+}